@@ -0,0 +1,164 @@
+// Package apierror turns errors into the JSON error responses of the API.
+// Every error body has the same shape, so clients can branch on code
+// instead of parsing messages:
+//
+//	{"code": "playlist_not_found", "message": "Playlist not found", "request_id": "..."}
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/logging"
+	"github.com/dmarquinah/publist_backend/internal/requestid"
+)
+
+// Error is an error with the status and machine-readable code it is
+// reported with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// Details is optional data about the failure, such as the parameter
+	// that was rejected.
+	Details any
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Errors raised by handlers rather than services.
+var (
+	ErrInvalidBody = New(http.StatusBadRequest, "invalid_body", "Invalid request body")
+	ErrForbidden   = New(http.StatusForbidden, "forbidden", "Forbidden")
+	ErrInternal    = New(http.StatusInternalServerError, "internal_error", "Internal server error")
+)
+
+// InvalidParameter reports a query, path or header parameter that could not
+// be parsed.
+func InvalidParameter(name string) *Error {
+	return New(http.StatusBadRequest, "invalid_parameter", "Invalid "+name).
+		WithDetails(map[string]string{"parameter": name})
+}
+
+// sentinels maps the errors of the errors package to API errors.
+var sentinels = []struct {
+	err error
+	api *Error
+}{
+	{errorsmsg.ErrItemNotFound, New(http.StatusNotFound, "not_found", "Item not found")},
+	{errorsmsg.ErrPlaylistNotFound, New(http.StatusNotFound, "playlist_not_found", "Playlist not found")},
+	{errorsmsg.ErrTrackNotFound, New(http.StatusNotFound, "track_not_found", "Track not found")},
+	{errorsmsg.ErrUnauthorized, New(http.StatusUnauthorized, "unauthorized", "Authentication required")},
+	{errorsmsg.ErrForbidden, ErrForbidden},
+	{errorsmsg.ErrInvalidName, New(http.StatusBadRequest, "invalid_playlist_name", "Invalid playlist name")},
+	{errorsmsg.ErrNameTooLong, New(http.StatusBadRequest, "playlist_name_too_long", "Playlist name too long")},
+	{errorsmsg.ErrInvalidPosition, New(http.StatusBadRequest, "invalid_position", "Invalid position")},
+	{errorsmsg.ErrNoTrackPlaying, New(http.StatusConflict, "no_track_playing", "No track is playing")},
+
+	{errorsmsg.ErrHostNotFound, New(http.StatusNotFound, "host_not_found", "Host not found")},
+	{errorsmsg.ErrInvalidHostName, New(http.StatusBadRequest, "invalid_host_name", "Invalid host name")},
+	{errorsmsg.ErrEmailTaken, New(http.StatusConflict, "email_taken", "Email already registered")},
+	{errorsmsg.ErrInvalidEmail, New(http.StatusBadRequest, "invalid_email", "Invalid email address")},
+	{errorsmsg.ErrInvalidPassword, New(http.StatusBadRequest, "invalid_password", "Password must be between 8 and 72 bytes long")},
+	{errorsmsg.ErrInvalidCredentials, New(http.StatusUnauthorized, "invalid_credentials", "Invalid email or password")},
+	{errorsmsg.ErrHostInactive, New(http.StatusForbidden, "host_inactive", "Host account is inactive")},
+	{errorsmsg.ErrInvalidRole, New(http.StatusBadRequest, "invalid_role", "Invalid role")},
+
+	{errorsmsg.ErrAlreadyMember, New(http.StatusConflict, "already_member", "Account is already a member of this playlist")},
+	{errorsmsg.ErrMemberNotFound, New(http.StatusNotFound, "member_not_found", "Member not found")},
+	{errorsmsg.ErrInvalidMemberRole, New(http.StatusBadRequest, "invalid_member_role", "Invalid member role")},
+	{errorsmsg.ErrCannotBeMember, New(http.StatusConflict, "cannot_be_member", "Account cannot be given this member role")},
+	{errorsmsg.ErrCannotRemoveOwner, New(http.StatusConflict, "cannot_remove_owner", "Playlist owner cannot be removed")},
+
+	{errorsmsg.ErrInvalidRefreshToken, New(http.StatusUnauthorized, "invalid_refresh_token", "Invalid refresh token")},
+	{errorsmsg.ErrRefreshTokenExpired, New(http.StatusUnauthorized, "refresh_token_expired", "Refresh token has expired")},
+	{errorsmsg.ErrRefreshTokenReused, New(http.StatusUnauthorized, "refresh_token_reused", "Refresh token reuse detected, session revoked")},
+
+	{errorsmsg.ErrInvalidTrackTitle, New(http.StatusBadRequest, "invalid_track_title", "Invalid track title")},
+	{errorsmsg.ErrInvalidArtist, New(http.StatusBadRequest, "invalid_artist", "Invalid artist")},
+	{errorsmsg.ErrInvalidDuration, New(http.StatusBadRequest, "invalid_duration", "Invalid track duration")},
+	{errorsmsg.ErrInvalidNickname, New(http.StatusBadRequest, "invalid_nickname", "Invalid nickname")},
+	{errorsmsg.ErrSongRequestNotFound, New(http.StatusNotFound, "song_request_not_found", "Song request not found")},
+	{errorsmsg.ErrSongRequestReviewed, New(http.StatusConflict, "song_request_reviewed", "Song request already reviewed")},
+	{errorsmsg.ErrTooManySongRequests, New(http.StatusTooManyRequests, "too_many_song_requests", "Too many song requests, try again later")},
+	{errorsmsg.ErrInvalidVote, New(http.StatusBadRequest, "invalid_vote", "Invalid vote")},
+
+	{errorsmsg.ErrTrackBlocked, New(http.StatusUnprocessableEntity, "track_blocked", "Track is blocked by moderation")},
+	{errorsmsg.ErrBlockRuleNotFound, New(http.StatusNotFound, "block_rule_not_found", "Block rule not found")},
+	{errorsmsg.ErrInvalidBlockRule, New(http.StatusBadRequest, "invalid_block_rule", "Invalid block rule")},
+	{errorsmsg.ErrModerationFlagNotFound, New(http.StatusNotFound, "moderation_flag_not_found", "Moderation flag not found")},
+	{errorsmsg.ErrModerationFlagReviewed, New(http.StatusConflict, "moderation_flag_reviewed", "Moderation flag already reviewed")},
+
+	{errorsmsg.ErrInvalidLimit, New(http.StatusBadRequest, "invalid_limit", "Invalid limit")},
+	{errorsmsg.ErrInvalidCursor, New(http.StatusBadRequest, "invalid_cursor", "Invalid cursor")},
+	{errorsmsg.ErrInvalidSort, New(http.StatusBadRequest, "invalid_sort", "Invalid sort")},
+
+	{errorsmsg.ErrInvalidBody, New(http.StatusBadRequest, "invalid_body", "Invalid request body")},
+	{errorsmsg.ErrBodyTooLarge, New(http.StatusRequestEntityTooLarge, "body_too_large", "Request body too large")},
+}
+
+// errValidation reports an *errors.ValidationError, with its field errors
+// as details.
+var errValidation = New(http.StatusBadRequest, "validation_failed", "Request validation failed")
+
+// From returns the API error err is reported as. Errors that are neither an
+// *Error nor one of the errors package's are internal errors.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var validationErr *errorsmsg.ValidationError
+	if errors.As(err, &validationErr) {
+		return errValidation.WithDetails(validationErr.Fields)
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.api
+		}
+	}
+	return ErrInternal
+}
+
+// body is the JSON shape of an error response.
+type body struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write replies to the request with err. Unexpected errors are logged and
+// reported as internal errors without leaking their text.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := From(err)
+	if apiErr == ErrInternal && err != ErrInternal {
+		logging.FromContext(r.Context()).Error("request failed", slog.Any("err", err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(body{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: requestid.FromContext(r.Context()),
+	})
+}
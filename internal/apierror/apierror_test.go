@@ -0,0 +1,75 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+)
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"unauthorized", errorsmsg.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{"forbidden", errorsmsg.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"not found", errorsmsg.ErrPlaylistNotFound, http.StatusNotFound, "playlist_not_found"},
+		{"conflict", errorsmsg.ErrNoTrackPlaying, http.StatusConflict, "no_track_playing"},
+		{"bad request", errorsmsg.ErrInvalidPassword, http.StatusBadRequest, "invalid_password"},
+		{"invalid credentials", errorsmsg.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{"inactive host", errorsmsg.ErrHostInactive, http.StatusForbidden, "host_inactive"},
+		{"too many requests", errorsmsg.ErrTooManySongRequests, http.StatusTooManyRequests, "too_many_song_requests"},
+		{"body too large", errorsmsg.ErrBodyTooLarge, http.StatusRequestEntityTooLarge, "body_too_large"},
+		{"blocked", errorsmsg.ErrTrackBlocked, http.StatusUnprocessableEntity, "track_blocked"},
+		{"wrapped sentinel", fmt.Errorf("authorizing: %w", errorsmsg.ErrForbidden), http.StatusForbidden, "forbidden"},
+		{"retry error", &errorsmsg.RetryError{Err: errorsmsg.ErrTooManySongRequests}, http.StatusTooManyRequests, "too_many_song_requests"},
+		{"validation error", &errorsmsg.ValidationError{}, http.StatusBadRequest, "validation_failed"},
+		{"api error", InvalidParameter("status"), http.StatusBadRequest, "invalid_parameter"},
+		{"unknown error", errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("From(%v) = %d %s, want %d %s", tt.err, got.Status, got.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// TestSentinels checks every sentinel maps to an error status with a code.
+func TestSentinels(t *testing.T) {
+	codes := make(map[string]error)
+	for _, s := range sentinels {
+		if s.api.Status < 400 || s.api.Code == "" || s.api.Message == "" {
+			t.Errorf("%v maps to %+v", s.err, s.api)
+		}
+		if other, ok := codes[s.api.Code]; ok {
+			t.Errorf("%v and %v share the code %q", s.err, other, s.api.Code)
+		}
+		codes[s.api.Code] = s.err
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, httptest.NewRequest("GET", "/", nil), errorsmsg.ErrUnauthorized)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	var got body
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if got.Code != "unauthorized" || got.Message == "" {
+		t.Errorf("body = %+v, want the unauthorized code and a message", got)
+	}
+}
@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+// claimsKey is the context key under which validated token claims are stored.
+type claimsKey struct{}
+
+// NewContext returns a copy of ctx carrying the given claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns the claims stored in ctx, if any.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmarquinah/publist_backend/internal/rbac"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -14,25 +15,36 @@ var (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"` // "host" or "admin"
+	UserID string    `json:"user_id"`
+	Role   rbac.Role `json:"role"`
 	jwt.RegisteredClaims
 }
 
+// Principal returns the account the token was issued to.
+func (c *Claims) Principal() rbac.Principal {
+	return rbac.Principal{UserID: c.UserID, Role: c.Role}
+}
+
 type JWTManager struct {
 	secretKey []byte
+	accessTTL time.Duration
+}
+
+func NewJWTManager(secretKey string, accessTTL time.Duration) *JWTManager {
+	return &JWTManager{secretKey: []byte(secretKey), accessTTL: accessTTL}
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
-	return &JWTManager{secretKey: []byte(secretKey)}
+// AccessTTL returns how long generated access tokens remain valid.
+func (m *JWTManager) AccessTTL() time.Duration {
+	return m.accessTTL
 }
 
-func (m *JWTManager) GenerateToken(userID, role string) (string, error) {
+func (m *JWTManager) GenerateToken(userID string, role rbac.Role) (string, error) {
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -2,13 +2,57 @@ package config
 
 import (
 	"log"
+	"log/slog"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Storage backends selectable with STORAGE.
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
-	ServerAddress string
+	ServerAddress   string
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	AutoAdvance     bool
+	// Storage is StorageDatabase or StorageMemory. The memory store needs no
+	// database and loses its data on restart.
+	Storage string
+	// LogLevel is the least severe level logged, and LogFormat is "json"
+	// or "text".
+	LogLevel  slog.Level
+	LogFormat string
+	// RateLimit throttles clients that make too many requests.
+	RateLimit bool
+	// TrustedProxies are the proxies whose X-Forwarded-For header is
+	// believed when telling clients apart.
+	TrustedProxies []netip.Prefix
+	// MetricsToken is the bearer token /metrics requires. MetricsAddress,
+	// if set, serves /metrics on a listener of its own instead, which can
+	// be kept off the public network.
+	MetricsToken   string
+	MetricsAddress string
+	// Tracing exports traces over OTLP, enabled by setting the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT.
+	Tracing bool
+	// HealthCheckTimeout bounds each readiness check. CacheAddress, if set,
+	// is the host:port of a Redis-compatible cache whose reachability is
+	// reported, without affecting readiness.
+	HealthCheckTimeout time.Duration
+	CacheAddress       string
+	// ShutdownDelay is how long the server keeps serving, while reporting
+	// itself not ready, before shutting down, so load balancers can stop
+	// sending it requests first.
+	ShutdownDelay time.Duration
 	// Add more configuration options here
 }
 
@@ -27,9 +71,25 @@ func New() *Config {
 	}
 
 	port := getEnv("APP_PORT", ":5000")
+	jwtSecret := getEnv("JWT_SECRET", "")
 
 	return &Config{
-		ServerAddress: port,
+		ServerAddress:      port,
+		JWTSecret:          jwtSecret,
+		AccessTokenTTL:     getEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL:    getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		AutoAdvance:        getEnvBool("AUTO_ADVANCE", false),
+		Storage:            getEnv("STORAGE", StorageDatabase),
+		LogLevel:           getEnvLevel("LOG_LEVEL", slog.LevelInfo),
+		LogFormat:          getEnv("LOG_FORMAT", "json"),
+		RateLimit:          getEnvBool("RATE_LIMIT", true),
+		TrustedProxies:     getEnvPrefixes("TRUSTED_PROXIES"),
+		MetricsToken:       getEnv("METRICS_TOKEN", ""),
+		MetricsAddress:     getEnv("METRICS_ADDRESS", ""),
+		Tracing:            os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "",
+		HealthCheckTimeout: getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		CacheAddress:       getEnv("CACHE_ADDRESS", ""),
+		ShutdownDelay:      getEnvDuration("SHUTDOWN_DELAY", 0),
 		// Initialize other config values
 	}
 }
@@ -41,3 +101,65 @@ func getEnv(key, fallback string) string {
 	}
 	return value
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid duration %q for %s, using %s", value, key, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid boolean %q for %s, using %t", value, key, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getEnvLevel(key string, fallback slog.Level) slog.Level {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		log.Printf("Warning: invalid log level %q for %s, using %s", value, key, fallback)
+		return fallback
+	}
+	return level
+}
+
+// getEnvPrefixes reads a comma-separated list of addresses and CIDR ranges,
+// skipping the invalid ones.
+func getEnvPrefixes(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			log.Printf("Warning: invalid address %q in %s, ignoring it", value, key)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
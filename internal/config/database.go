@@ -4,11 +4,22 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"time"
 
 	_ "database/sql/driver"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Database drivers selectable with DB_DRIVER.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 const DB_HOST_KEY = "DB_HOST"
@@ -16,38 +27,110 @@ const DB_PORT_KEY = "DB_PORT"
 const DB_PASSWORD_KEY = "DB_PASSWORD"
 const DB_USER_KEY = "DB_USER"
 const DB_NAME_KEY = "DB_NAME"
+const DB_AUTO_MIGRATE_KEY = "DB_AUTO_MIGRATE"
+const DB_DRIVER_KEY = "DB_DRIVER"
+const DB_SSLMODE_KEY = "DB_SSLMODE"
+const DB_PATH_KEY = "DB_PATH"
+const DB_BUSY_TIMEOUT_KEY = "DB_BUSY_TIMEOUT"
 
 type DBConfig struct {
+	// Driver is DriverMySQL, DriverPostgres or DriverSQLite.
+	Driver   string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+	// SSLMode is passed to Postgres as sslmode.
+	SSLMode string
+	// Path is the SQLite database file.
+	Path string
+	// BusyTimeout is how long SQLite waits for a lock held by another
+	// connection before failing.
+	BusyTimeout time.Duration
+	// AutoMigrate applies pending migrations on startup.
+	AutoMigrate bool
 }
 
 func NewDBConfig() *DBConfig {
+	dbDriver := getEnv(DB_DRIVER_KEY, DriverMySQL)
+	defaultPort := "3306"
+	if dbDriver == DriverPostgres {
+		defaultPort = "5432"
+	}
 	dbHost := getEnv(DB_HOST_KEY, "localhost")
-	dbPort := getEnv(DB_PORT_KEY, "3306")
+	dbPort := getEnv(DB_PORT_KEY, defaultPort)
 	dbUser := getEnv(DB_USER_KEY, "root")
 	dbPassword := getEnv(DB_PASSWORD_KEY, "root")
 	dbName := getEnv(DB_NAME_KEY, "pubplay")
 
 	return &DBConfig{
+		Driver:   dbDriver,
 		Host:     dbHost,
 		Port:     dbPort,
 		User:     dbUser,
 		Password: dbPassword,
 		DBName:   dbName,
+		SSLMode:  getEnv(DB_SSLMODE_KEY, "disable"),
+		Path:     getEnv(DB_PATH_KEY, "publist.db"),
+
+		BusyTimeout: getEnvDuration(DB_BUSY_TIMEOUT_KEY, 5*time.Second),
+
+		AutoMigrate: getEnvBool(DB_AUTO_MIGRATE_KEY, false),
 	}
 }
 
 func (c *DBConfig) DSN() string {
+	if c.Driver == DriverSQLite {
+		// WAL lets readers proceed while a write is in progress, and
+		// immediate transactions take the write lock up front so they
+		// wait on the busy timeout instead of failing halfway through
+		params := url.Values{
+			"_pragma": {
+				fmt.Sprintf("busy_timeout(%d)", c.BusyTimeout.Milliseconds()),
+				"journal_mode(WAL)",
+				"foreign_keys(1)",
+				"synchronous(NORMAL)",
+			},
+			"_txlock":      {"immediate"},
+			"_time_format": {"sqlite"},
+		}
+		return "file:" + c.Path + "?" + params.Encode()
+	}
+	if c.Driver == DriverPostgres {
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.User, c.Password),
+			Host:     net.JoinHostPort(c.Host, c.Port),
+			Path:     c.DBName,
+			RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+		}
+		return u.String()
+	}
 	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		c.User, c.Password, c.Host, c.Port, c.DBName)
 }
 
+// driverName maps Driver to the registered database/sql driver.
+func (c *DBConfig) driverName() (string, error) {
+	switch c.Driver {
+	case DriverMySQL:
+		return "mysql", nil
+	case DriverPostgres:
+		return "pgx", nil
+	case DriverSQLite:
+		return "sqlite", nil
+	default:
+		return "", fmt.Errorf("unknown %s %q", DB_DRIVER_KEY, c.Driver)
+	}
+}
+
 func NewDB(config *DBConfig) (*sql.DB, error) {
-	db, err := sql.Open("mysql", config.DSN())
+	driverName, err := config.driverName()
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driverName, config.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
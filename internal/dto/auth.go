@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"fmt"
+
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+)
+
+const (
+	maxEmailLength    = 255
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+)
+
+// RegisterRequest is the body for creating an account. Role defaults to
+// host; admins are appointed, so they can't register themselves.
+type RegisterRequest struct {
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Password string    `json:"password"`
+	Role     rbac.Role `json:"role"`
+}
+
+// Normalize leaves the password alone: it is hashed byte for byte, and
+// normalizing it would lock out hosts who registered before.
+func (r *RegisterRequest) Normalize() {
+	r.Name = normalize(r.Name)
+	r.Email = normalize(r.Email)
+	r.Role = rbac.Role(normalize(string(r.Role)))
+}
+
+func (r *RegisterRequest) Validate() error {
+	var v validator
+	v.text("name", r.Name, true, maxTextLength)
+	v.email("email", r.Email)
+	if r.Role != "" && (!r.Role.Valid() || r.Role == rbac.RoleAdmin) {
+		v.add("role", CodeInvalidChoice, "must be one of host, staff or viewer")
+	}
+	switch {
+	case r.Password == "":
+		v.add("password", CodeRequired, "is required")
+	case len(r.Password) < minPasswordLength:
+		v.add("password", CodeTooShort, fmt.Sprintf("must be at least %d bytes", minPasswordLength))
+	case len(r.Password) > maxPasswordLength:
+		v.add("password", CodeTooLong, fmt.Sprintf("must be at most %d bytes", maxPasswordLength))
+	}
+	return v.err()
+}
+
+// LoginRequest is the body for logging in with email and password.
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (r *LoginRequest) Normalize() {
+	r.Email = normalize(r.Email)
+}
+
+func (r *LoginRequest) Validate() error {
+	var v validator
+	v.text("email", r.Email, true, maxEmailLength)
+	if r.Password == "" {
+		v.add("password", CodeRequired, "is required")
+	}
+	return v.err()
+}
+
+// RefreshRequest carries a refresh token, to rotate or to revoke.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *RefreshRequest) Normalize() {
+	r.RefreshToken = normalize(r.RefreshToken)
+}
+
+func (r *RefreshRequest) Validate() error {
+	var v validator
+	if r.RefreshToken == "" {
+		v.add("refresh_token", CodeRequired, "is required")
+	}
+	return v.err()
+}
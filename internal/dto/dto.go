@@ -0,0 +1,159 @@
+// Package dto defines the request bodies accepted by the API. They are
+// decoded strictly, normalized and validated before being turned into model
+// values, so services only ever see well-formed input.
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxBodySize bounds request bodies; none of them comes close.
+const maxBodySize = 1 << 20
+
+// Field error codes.
+const (
+	CodeRequired          = "required"
+	CodeTooShort          = "too_short"
+	CodeTooLong           = "too_long"
+	CodeOutOfRange        = "out_of_range"
+	CodeInvalidFormat     = "invalid_format"
+	CodeInvalidCharacters = "invalid_characters"
+	CodeInvalidChoice     = "invalid_choice"
+	CodeInvalidType       = "invalid_type"
+	CodeUnknownField      = "unknown_field"
+)
+
+// Request is a request body. Normalize is called once it is decoded and
+// before Validate.
+type Request interface {
+	Normalize()
+	Validate() error
+}
+
+// Decode reads the JSON body of r into req, rejecting unknown fields and
+// trailing data, then normalizes and validates it. Problems with individual
+// fields are reported as an *errors.ValidationError.
+func Decode(w http.ResponseWriter, r *http.Request, req Request) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		return decodeError(err)
+	}
+	// Anything but whitespace after the value is trailing data
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return errorsmsg.ErrInvalidBody
+	}
+
+	req.Normalize()
+	return req.Validate()
+}
+
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errorsmsg.ErrBodyTooLarge
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fieldError(typeErr.Field, CodeInvalidType, "must be of type "+jsonType(typeErr.Type.Kind().String()))
+	}
+
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			field = unquoted
+		}
+		return fieldError(field, CodeUnknownField, "is not a known field")
+	}
+
+	return errorsmsg.ErrInvalidBody
+}
+
+// jsonType names a Go kind the way API clients know it.
+func jsonType(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "bool":
+		return "boolean"
+	case kind == "struct", kind == "map":
+		return "object"
+	case kind == "slice", kind == "array":
+		return "array"
+	default:
+		return kind
+	}
+}
+
+func fieldError(field, code, message string) error {
+	return &errorsmsg.ValidationError{Fields: []errorsmsg.FieldError{{Field: field, Code: code, Message: message}}}
+}
+
+// normalize puts user-entered text in Unicode NFC form, so visually equal
+// strings compare and count equal, and trims surrounding whitespace.
+func normalize(s string) string {
+	return strings.TrimSpace(norm.NFC.String(s))
+}
+
+// validator collects the field errors of a request.
+type validator struct {
+	fields []errorsmsg.FieldError
+}
+
+func (v *validator) add(field, code, message string) {
+	v.fields = append(v.fields, errorsmsg.FieldError{Field: field, Code: code, Message: message})
+}
+
+// text checks a normalized single-line text field of at most maxLength
+// characters.
+func (v *validator) text(field, value string, required bool, maxLength int) {
+	switch {
+	case value == "":
+		if required {
+			v.add(field, CodeRequired, "is required")
+		}
+	case utf8.RuneCountInString(value) > maxLength:
+		v.add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", maxLength))
+	case strings.IndexFunc(value, unicode.IsControl) >= 0:
+		v.add(field, CodeInvalidCharacters, "must not contain control characters")
+	}
+}
+
+// email checks a plain address such as host@example.com, without a display
+// name.
+func (v *validator) email(field, value string) {
+	n := len(v.fields)
+	v.text(field, value, true, maxEmailLength)
+	if len(v.fields) > n {
+		return
+	}
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		v.add(field, CodeInvalidFormat, "must be an email address")
+	}
+}
+
+func (v *validator) min(field string, value, min int) {
+	if value < min {
+		v.add(field, CodeOutOfRange, fmt.Sprintf("must be at least %d", min))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &errorsmsg.ValidationError{Fields: v.fields}
+}
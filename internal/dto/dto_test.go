@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+)
+
+func decode(body string, req Request) error {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	return Decode(httptest.NewRecorder(), r, req)
+}
+
+// fields returns the field errors of a ValidationError, or nil for any
+// other error.
+func fields(err error) []errorsmsg.FieldError {
+	var validationErr *errorsmsg.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"valid", `{"name": "Friday"}`, nil},
+		{"trailing whitespace", "{\"name\": \"Friday\"}\n\t ", nil},
+		{"trailing object", `{"name": "Friday"}{}`, errorsmsg.ErrInvalidBody},
+		{"trailing garbage", `{"name": "Friday"} x`, errorsmsg.ErrInvalidBody},
+		{"trailing bracket", `{"name": "Friday"}]`, errorsmsg.ErrInvalidBody},
+		{"empty", ``, errorsmsg.ErrInvalidBody},
+		{"malformed", `{"name": `, errorsmsg.ErrInvalidBody},
+		{"too large", `{"name": "` + strings.Repeat("a", maxBodySize) + `"}`, errorsmsg.ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decode(tt.body, &PlaylistRequest{})
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		req  Request
+		want []errorsmsg.FieldError
+	}{
+		{
+			"unknown field",
+			`{"name": "Friday", "owner": "me"}`,
+			&PlaylistRequest{},
+			[]errorsmsg.FieldError{{Field: "owner", Code: CodeUnknownField, Message: "is not a known field"}},
+		},
+		{
+			"wrong type",
+			`{"name": 42}`,
+			&PlaylistRequest{},
+			[]errorsmsg.FieldError{{Field: "name", Code: CodeInvalidType, Message: "must be of type string"}},
+		},
+		{
+			// Every invalid field is reported, in the order checked
+			"several fields",
+			`{"title": " ", "artist": "a\u0007b", "duration": -1, "nickname": "` + strings.Repeat("n", maxNicknameLength+1) + `"}`,
+			&SongRequestSubmission{},
+			[]errorsmsg.FieldError{
+				{Field: "title", Code: CodeRequired, Message: "is required"},
+				{Field: "artist", Code: CodeInvalidCharacters, Message: "must not contain control characters"},
+				{Field: "duration", Code: CodeOutOfRange, Message: "must be at least 0"},
+				{Field: "nickname", Code: CodeTooLong, Message: "must be at most 50 characters"},
+			},
+		},
+		{
+			// Passwords are limited in bytes, as bcrypt counts them
+			"password bytes",
+			`{"name": "Host", "email": "host@example.com", "password": "` + strings.Repeat("\u00e9", 37) + `"}`,
+			&RegisterRequest{},
+			[]errorsmsg.FieldError{{Field: "password", Code: CodeTooLong, Message: "must be at most 72 bytes"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decode(tt.body, tt.req)
+			if got := fields(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field errors = %+v, want %+v (err %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDecodeNormalizes(t *testing.T) {
+	// "e" followed by a combining acute accent is "\u00e9" in NFC
+	var req SongRequestSubmission
+	body := `{"title": "  Cafe\u0301  ", "nickname": "` + strings.Repeat("e\u0301", maxNicknameLength) + `"}`
+	if err := decode(body, &req); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if want := "Caf\u00e9"; req.Title != want {
+		t.Errorf("Title = %+q, want %+q", req.Title, want)
+	}
+	// Lengths are counted after normalization, so the nickname fits
+	if want := strings.Repeat("\u00e9", maxNicknameLength); req.Nickname != want {
+		t.Errorf("Nickname = %+q, want %+q", req.Nickname, want)
+	}
+}
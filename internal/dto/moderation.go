@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+// BlockRuleRequest is the body for adding a rule to a blocklist. Action
+// defaults to reject.
+type BlockRuleRequest struct {
+	Kind    model.BlockKind   `json:"kind"`
+	Pattern string            `json:"pattern"`
+	Action  model.BlockAction `json:"action"`
+}
+
+func (r *BlockRuleRequest) Normalize() {
+	r.Kind = model.BlockKind(normalize(string(r.Kind)))
+	r.Pattern = normalize(r.Pattern)
+	r.Action = model.BlockAction(normalize(string(r.Action)))
+	if r.Action == "" {
+		r.Action = model.BlockReject
+	}
+}
+
+func (r *BlockRuleRequest) Validate() error {
+	var v validator
+	switch r.Kind {
+	case model.BlockArtist, model.BlockTitle, model.BlockWord:
+	case "":
+		v.add("kind", CodeRequired, "is required")
+	default:
+		v.add("kind", CodeInvalidChoice, "must be artist, title or word")
+	}
+	v.text("pattern", r.Pattern, true, maxTextLength)
+	switch r.Action {
+	case model.BlockReject, model.BlockFlag:
+	default:
+		v.add("action", CodeInvalidChoice, "must be reject or flag")
+	}
+	return v.err()
+}
+
+func (r *BlockRuleRequest) BlockRule() *model.BlockRule {
+	return &model.BlockRule{
+		Kind:    r.Kind,
+		Pattern: r.Pattern,
+		Action:  r.Action,
+	}
+}
@@ -0,0 +1,138 @@
+package dto
+
+import (
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/google/uuid"
+)
+
+// maxTextLength is the length of the name, title and artist columns.
+const maxTextLength = 255
+
+// PlaylistRequest is the body for creating or updating a playlist.
+type PlaylistRequest struct {
+	Name        string `json:"name"`
+	VoteOrdered bool   `json:"vote_ordered"`
+}
+
+func (r *PlaylistRequest) Normalize() {
+	r.Name = normalize(r.Name)
+}
+
+func (r *PlaylistRequest) Validate() error {
+	var v validator
+	v.text("name", r.Name, true, maxTextLength)
+	return v.err()
+}
+
+func (r *PlaylistRequest) Playlist() *model.Playlist {
+	return &model.Playlist{
+		Name:        r.Name,
+		VoteOrdered: r.VoteOrdered,
+	}
+}
+
+// AddTrackRequest adds a catalog track by ID, or a new track described by
+// title, artist and duration.
+type AddTrackRequest struct {
+	TrackID  string `json:"track_id"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Duration int    `json:"duration"` // in seconds
+}
+
+func (r *AddTrackRequest) Normalize() {
+	r.TrackID = normalize(r.TrackID)
+	r.Title = normalize(r.Title)
+	r.Artist = normalize(r.Artist)
+}
+
+func (r *AddTrackRequest) Validate() error {
+	var v validator
+	if r.TrackID != "" {
+		if _, err := uuid.Parse(r.TrackID); err != nil {
+			v.add("track_id", CodeInvalidFormat, "must be a UUID")
+		}
+	}
+	// The catalog supplies the details of a track added by ID
+	v.text("title", r.Title, r.TrackID == "", maxTextLength)
+	v.text("artist", r.Artist, false, maxTextLength)
+	v.min("duration", r.Duration, 0)
+	return v.err()
+}
+
+func (r *AddTrackRequest) Track() *model.Playlist_Track {
+	return &model.Playlist_Track{
+		TrackID:  r.TrackID,
+		Title:    r.Title,
+		Artist:   r.Artist,
+		Duration: r.Duration,
+	}
+}
+
+// ReorderTrackRequest moves a track to a 1-based position.
+type ReorderTrackRequest struct {
+	Position int `json:"position"`
+}
+
+func (r *ReorderTrackRequest) Normalize() {}
+
+func (r *ReorderTrackRequest) Validate() error {
+	var v validator
+	v.min("position", r.Position, 1)
+	return v.err()
+}
+
+// PlayTrackRequest starts playing a track of the playlist.
+type PlayTrackRequest struct {
+	TrackID string `json:"track_id"`
+}
+
+func (r *PlayTrackRequest) Normalize() {
+	r.TrackID = normalize(r.TrackID)
+}
+
+func (r *PlayTrackRequest) Validate() error {
+	var v validator
+	v.text("track_id", r.TrackID, true, maxTextLength)
+	return v.err()
+}
+
+// ModeratePlaylistRequest turns moderation of a playlist on or off.
+type ModeratePlaylistRequest struct {
+	IsModerated *bool `json:"is_moderated"`
+}
+
+func (r *ModeratePlaylistRequest) Normalize() {}
+
+func (r *ModeratePlaylistRequest) Validate() error {
+	var v validator
+	if r.IsModerated == nil {
+		v.add("is_moderated", CodeRequired, "is required")
+	}
+	return v.err()
+}
+
+// AddMemberRequest names the account to share a playlist with, and its role
+// in the playlist.
+type AddMemberRequest struct {
+	Email string          `json:"email"`
+	Role  rbac.MemberRole `json:"role"`
+}
+
+func (r *AddMemberRequest) Normalize() {
+	r.Email = normalize(r.Email)
+	r.Role = rbac.MemberRole(normalize(string(r.Role)))
+}
+
+func (r *AddMemberRequest) Validate() error {
+	var v validator
+	v.email("email", r.Email)
+	switch {
+	case r.Role == "":
+		v.add("role", CodeRequired, "is required")
+	case r.Role != rbac.MemberEditor && r.Role != rbac.MemberViewer:
+		v.add("role", CodeInvalidChoice, "must be editor or viewer")
+	}
+	return v.err()
+}
@@ -0,0 +1,37 @@
+package dto
+
+import "github.com/dmarquinah/publist_backend/internal/model"
+
+const maxNicknameLength = 50
+
+// SongRequestSubmission is the body a guest sends to request a song.
+type SongRequestSubmission struct {
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Duration int    `json:"duration"` // in seconds
+	Nickname string `json:"nickname"`
+}
+
+func (r *SongRequestSubmission) Normalize() {
+	r.Title = normalize(r.Title)
+	r.Artist = normalize(r.Artist)
+	r.Nickname = normalize(r.Nickname)
+}
+
+func (r *SongRequestSubmission) Validate() error {
+	var v validator
+	v.text("title", r.Title, true, maxTextLength)
+	v.text("artist", r.Artist, false, maxTextLength)
+	v.min("duration", r.Duration, 0)
+	v.text("nickname", r.Nickname, true, maxNicknameLength)
+	return v.err()
+}
+
+func (r *SongRequestSubmission) SongRequest() *model.SongRequest {
+	return &model.SongRequest{
+		Title:    r.Title,
+		Artist:   r.Artist,
+		Duration: r.Duration,
+		Nickname: r.Nickname,
+	}
+}
@@ -1,14 +1,96 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 var (
 	ErrItemNotFound     = errors.New("item not found")
 	ErrPlaylistNotFound = errors.New("playlist not found")
 	ErrTrackNotFound    = errors.New("track not found")
-	ErrUnauthorized     = errors.New("unauthorized")
 	ErrInvalidName      = errors.New("invalid playlist name")
 	ErrNameTooLong      = errors.New("playlist name too long")
 	ErrInvalidPosition  = errors.New("invalid track position")
+	ErrNoTrackPlaying   = errors.New("no track is playing")
+
+	// ErrUnauthorized is for requests without valid credentials, and
+	// ErrForbidden for authenticated ones lacking a permission.
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+
+	ErrHostNotFound       = errors.New("host not found")
+	ErrInvalidHostName    = errors.New("invalid host name")
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrInvalidPassword    = errors.New("password must be between 8 and 72 bytes long")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrHostInactive       = errors.New("host account is inactive")
+	ErrInvalidRole        = errors.New("invalid role")
+
+	ErrAlreadyMember     = errors.New("account is already a member of this playlist")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrInvalidMemberRole = errors.New("invalid member role")
+	ErrCannotBeMember    = errors.New("account cannot be given this member role")
+	ErrCannotRemoveOwner = errors.New("playlist owner cannot be removed")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+
+	ErrInvalidTrackTitle   = errors.New("invalid track title")
+	ErrInvalidArtist       = errors.New("invalid artist")
+	ErrInvalidDuration     = errors.New("invalid track duration")
+	ErrInvalidNickname     = errors.New("invalid nickname")
+	ErrSongRequestNotFound = errors.New("song request not found")
+	ErrSongRequestReviewed = errors.New("song request already reviewed")
+	ErrTooManySongRequests = errors.New("too many song requests")
+	ErrInvalidVote         = errors.New("invalid vote")
+
+	ErrTrackBlocked           = errors.New("track is blocked by moderation")
+	ErrBlockRuleNotFound      = errors.New("block rule not found")
+	ErrInvalidBlockRule       = errors.New("invalid block rule")
+	ErrModerationFlagNotFound = errors.New("moderation flag not found")
+	ErrModerationFlagReviewed = errors.New("moderation flag already reviewed")
+
+	ErrInvalidLimit  = errors.New("invalid page limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+
+	ErrInvalidBody  = errors.New("invalid request body")
+	ErrBodyTooLarge = errors.New("request body too large")
 	// Add more custom errors as needed
 )
+
+// RetryError is returned when a limit is hit. It wraps the limit's error
+// and says how long until the client may try again.
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string { return e.Err.Error() }
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// FieldError describes why one field of a request was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned for a request with invalid fields. It lists
+// every problem found rather than only the first.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		problems[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(problems, "; ")
+}
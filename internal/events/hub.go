@@ -0,0 +1,284 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	TrackAdded        EventType = "track.added"
+	TrackRemoved      EventType = "track.removed"
+	TrackReordered    EventType = "track.reordered"
+	TracksReordered   EventType = "tracks.reordered"
+	NowPlayingChanged EventType = "now_playing.changed"
+	TrackVoted        EventType = "track.voted"
+)
+
+const (
+	// DefaultReplaySize is how many events are kept per playlist for
+	// subscribers resuming with a Last-Event-ID.
+	DefaultReplaySize = 100
+	// DefaultRetention is how long a playlist's events are kept once it has
+	// no subscribers, for clients resuming after a disconnect.
+	DefaultRetention = 10 * time.Minute
+	// sweepInterval is how often topics past their retention are looked
+	// for and dropped.
+	sweepInterval = time.Minute
+	// subscriberBuffer is how many events a subscriber may fall behind
+	// before it is dropped.
+	subscriberBuffer = 64
+)
+
+type Event struct {
+	ID         uint64    `json:"id"`
+	PlaylistID string    `json:"playlist_id"`
+	Type       EventType `json:"type"`
+	Data       any       `json:"data,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Publisher is implemented by anything that broadcasts playlist events.
+type Publisher interface {
+	Publish(playlistID string, eventType EventType, data any)
+	// Drop ends the subscriptions to a playlist and forgets its events,
+	// for playlists that are gone.
+	Drop(playlistID string)
+}
+
+// Hub is an in-process pub/sub broker for playlist events. Every playlist has
+// its own topic with a bounded replay buffer.
+type Hub struct {
+	mu         sync.Mutex
+	startID    uint64
+	nextID     uint64
+	replaySize int
+	retention  time.Duration
+	topics     map[string]*topic
+	lastSweep  time.Time
+	closed     bool
+	done       chan struct{}
+	// now is the clock retention is measured with, replaced in tests.
+	now func() time.Time
+}
+
+type topic struct {
+	buffer      []Event
+	evicted     uint64 // ID of the newest event dropped from the buffer
+	subscribers map[*Subscription]struct{}
+	// active is when the topic was last published to or left by its last
+	// subscriber, from which its retention runs.
+	active time.Time
+}
+
+// NewHub returns a hub keeping replaySize events per playlist, for as long
+// as the playlist has subscribers and for retention after that.
+func NewHub(replaySize int, retention time.Duration) *Hub {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	// Seed IDs from the clock so IDs handed out before a restart are always
+	// lower than those handed out after it.
+	start := uint64(time.Now().UnixMicro())
+	return &Hub{
+		startID:    start,
+		nextID:     start,
+		replaySize: replaySize,
+		retention:  retention,
+		topics:     make(map[string]*topic),
+		lastSweep:  time.Now(),
+		done:       make(chan struct{}),
+		now:        time.Now,
+	}
+}
+
+func (h *Hub) Publish(playlistID string, eventType EventType, data any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	now := h.now()
+	h.sweep(now)
+
+	t := h.topic(playlistID)
+	h.nextID++
+	event := Event{
+		ID:         h.nextID,
+		PlaylistID: playlistID,
+		Type:       eventType,
+		Data:       data,
+		CreatedAt:  now,
+	}
+	t.active = now
+	if len(t.buffer) == h.replaySize {
+		t.evicted = t.buffer[0].ID
+		t.buffer = append(t.buffer[:0], t.buffer[1:]...)
+	}
+	t.buffer = append(t.buffer, event)
+
+	for sub := range t.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			// Too slow to keep up; the client resumes from its last ID.
+			h.remove(t, sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for a playlist. When lastEventID is non
+// zero, the buffered events published after it are returned in
+// Subscription.Replay.
+func (h *Hub) Subscribe(playlistID string, lastEventID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{
+		PlaylistID: playlistID,
+		ch:         make(chan Event, subscriberBuffer),
+		hub:        h,
+	}
+	sub.C = sub.ch
+
+	if h.closed {
+		close(sub.ch)
+		return sub
+	}
+
+	h.sweep(h.now())
+
+	t := h.topic(playlistID)
+	if lastEventID != 0 {
+		sub.Missed = lastEventID < h.startID || lastEventID < t.evicted
+		for _, event := range t.buffer {
+			if event.ID > lastEventID {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+	t.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns the number of active subscriptions across all playlists.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, t := range h.topics {
+		n += len(t.subscribers)
+	}
+	return n
+}
+
+// Done returns a channel that is closed when the hub is closed.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Close ends every subscription and stops accepting events.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	close(h.done)
+	for _, t := range h.topics {
+		for sub := range t.subscribers {
+			h.remove(t, sub)
+		}
+	}
+}
+
+// Drop ends every subscription to a playlist and drops its buffered events.
+func (h *Hub) Drop(playlistID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[playlistID]
+	if !ok {
+		return
+	}
+	for sub := range t.subscribers {
+		h.remove(t, sub)
+	}
+	delete(h.topics, playlistID)
+}
+
+// topic returns the topic of a playlist, creating it if needed. A new
+// topic can't tell which events of the playlist were published before a
+// previous one was dropped, so it reports every earlier event as missed.
+// The caller holds h.mu.
+func (h *Hub) topic(playlistID string) *topic {
+	t, ok := h.topics[playlistID]
+	if !ok {
+		t = &topic{
+			evicted:     h.nextID,
+			subscribers: make(map[*Subscription]struct{}),
+			active:      h.now(),
+		}
+		h.topics[playlistID] = t
+	}
+	return t
+}
+
+// sweep drops the topics nobody has followed or published to for the
+// retention period. The caller holds h.mu.
+func (h *Hub) sweep(now time.Time) {
+	if now.Sub(h.lastSweep) < sweepInterval {
+		return
+	}
+	h.lastSweep = now
+	for playlistID, t := range h.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.active) >= h.retention {
+			delete(h.topics, playlistID)
+		}
+	}
+}
+
+// remove must be called with h.mu held.
+func (h *Hub) remove(t *topic, sub *Subscription) {
+	if _, ok := t.subscribers[sub]; !ok {
+		return
+	}
+	delete(t.subscribers, sub)
+	close(sub.ch)
+	if len(t.subscribers) == 0 {
+		t.active = h.now()
+	}
+}
+
+type Subscription struct {
+	PlaylistID string
+	// C delivers live events. It is closed when the subscription ends,
+	// either by Unsubscribe, by the hub closing, or by falling too far behind.
+	C <-chan Event
+	// Replay holds the buffered events missed since the requested ID.
+	Replay []Event
+	// Missed reports that some events after the requested ID are no longer
+	// buffered, so the client should refetch the full playlist state.
+	Missed bool
+
+	ch  chan Event
+	hub *Hub
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if t, ok := s.hub.topics[s.PlaylistID]; ok {
+		s.hub.remove(t, s)
+	}
+}
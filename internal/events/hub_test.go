@@ -0,0 +1,217 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestHub returns a hub on a clock that only moves when told to.
+func newTestHub(t *testing.T, replaySize int, retention time.Duration) (*Hub, *time.Time) {
+	t.Helper()
+	h := NewHub(replaySize, retention)
+	t.Cleanup(h.Close)
+
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	h.lastSweep = now
+	return h, &now
+}
+
+// publish publishes n events to a playlist and returns their IDs.
+func publish(t *testing.T, h *Hub, playlistID string, n int) []uint64 {
+	t.Helper()
+	sub := h.Subscribe(playlistID, 0)
+	defer sub.Unsubscribe()
+
+	ids := make([]uint64, n)
+	for i := range ids {
+		h.Publish(playlistID, TrackAdded, i)
+		ids[i] = (<-sub.C).ID
+	}
+	return ids
+}
+
+func replayIDs(sub *Subscription) []uint64 {
+	var ids []uint64
+	for _, event := range sub.Replay {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// closed reports whether a subscription's channel has been closed, after
+// draining what was already delivered.
+func closed(sub *Subscription) bool {
+	for {
+		select {
+		case _, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+func TestHubReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		resume int // index of the event to resume from, or -1
+		want   []int
+	}{
+		{"new subscriber", -1, nil},
+		{"resume from the first event", 0, []int{1, 2}},
+		{"resume from the last event", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHub(t, 10, time.Minute)
+			ids := publish(t, h, "p1", 3)
+			publish(t, h, "p2", 1)
+
+			var lastEventID uint64
+			if tt.resume >= 0 {
+				lastEventID = ids[tt.resume]
+			}
+			var want []uint64
+			for _, i := range tt.want {
+				want = append(want, ids[i])
+			}
+
+			sub := h.Subscribe("p1", lastEventID)
+			defer sub.Unsubscribe()
+
+			if got := replayIDs(sub); !equalIDs(got, want) {
+				t.Errorf("Replay = %v, want %v", got, want)
+			}
+			if sub.Missed {
+				t.Error("Missed = true, want false")
+			}
+
+			// Live events follow the replay
+			h.Publish("p1", TrackRemoved, nil)
+			if event := <-sub.C; event.Type != TrackRemoved {
+				t.Errorf("live event = %q, want %q", event.Type, TrackRemoved)
+			}
+		})
+	}
+}
+
+func TestHubReplayMissed(t *testing.T) {
+	h, _ := newTestHub(t, 2, time.Minute)
+	ids := publish(t, h, "p1", 3)
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		want        []uint64
+		missed      bool
+	}{
+		{"older than the buffer", ids[0] - 1, ids[1:], true},
+		{"last evicted event", ids[0], ids[1:], false},
+		{"buffered event", ids[1], ids[2:], false},
+		{"from before a restart", 1, ids[1:], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := h.Subscribe("p1", tt.lastEventID)
+			defer sub.Unsubscribe()
+
+			if got := replayIDs(sub); !equalIDs(got, tt.want) {
+				t.Errorf("Replay = %v, want %v", got, tt.want)
+			}
+			if sub.Missed != tt.missed {
+				t.Errorf("Missed = %v, want %v", sub.Missed, tt.missed)
+			}
+		})
+	}
+}
+
+func TestHubRetention(t *testing.T) {
+	h, now := newTestHub(t, 10, 5*time.Minute)
+	ids := publish(t, h, "p1", 2)
+
+	// Sweeps run on publish and subscribe, at most once per sweepInterval
+	*now = now.Add(5*time.Minute - time.Second)
+	h.Publish("p2", TrackAdded, nil)
+	sub := h.Subscribe("p1", ids[0])
+	if got := replayIDs(sub); !equalIDs(got, ids[1:]) {
+		t.Errorf("Replay within retention = %v, want %v", got, ids[1:])
+	}
+
+	// A subscriber keeps the topic however long it stays
+	*now = now.Add(time.Hour)
+	h.Publish("p2", TrackAdded, nil)
+	if _, ok := h.topics["p1"]; !ok {
+		t.Fatal("topic with a subscriber was dropped")
+	}
+
+	// Retention runs from when the last subscriber left
+	sub.Unsubscribe()
+	*now = now.Add(5 * time.Minute)
+	h.Publish("p2", TrackAdded, nil)
+	if _, ok := h.topics["p1"]; ok {
+		t.Fatal("topic was kept past its retention")
+	}
+
+	resumed := h.Subscribe("p1", ids[0])
+	defer resumed.Unsubscribe()
+	if len(resumed.Replay) != 0 || !resumed.Missed {
+		t.Errorf("after retention: Replay = %v, Missed = %v; want none and true", replayIDs(resumed), resumed.Missed)
+	}
+}
+
+func TestHubDrop(t *testing.T) {
+	h, _ := newTestHub(t, 10, time.Minute)
+	subs := []*Subscription{h.Subscribe("p1", 0), h.Subscribe("p1", 0)}
+	other := h.Subscribe("p2", 0)
+	defer other.Unsubscribe()
+	h.Publish("p1", TrackAdded, nil)
+
+	h.Drop("p1")
+
+	for i, sub := range subs {
+		if !closed(sub) {
+			t.Errorf("subscription %d is still open", i)
+		}
+		sub.Unsubscribe() // no-op once dropped
+	}
+	if closed(other) {
+		t.Error("subscription to another playlist was closed")
+	}
+	if got := h.Subscribers(); got != 1 {
+		t.Errorf("Subscribers() = %d, want 1", got)
+	}
+}
+
+func TestHubDropRecreate(t *testing.T) {
+	h, _ := newTestHub(t, 10, time.Minute)
+	old := publish(t, h, "p1", 2)
+
+	h.Drop("p1")
+	fresh := publish(t, h, "p1", 1)
+
+	// Events from before the drop are never replayed, and the gap is
+	// reported so the client refetches
+	sub := h.Subscribe("p1", old[0])
+	defer sub.Unsubscribe()
+	if got := replayIDs(sub); !equalIDs(got, fresh) {
+		t.Errorf("Replay = %v, want %v", got, fresh)
+	}
+	if !sub.Missed {
+		t.Error("Missed = false, want true")
+	}
+}
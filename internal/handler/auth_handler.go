@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/dto"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+type AuthHandler struct {
+	svc service.AuthService
+}
+
+func NewAuthHandler(svc service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		svc: svc,
+	}
+}
+
+func (h *AuthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /auth/register", h.Register)
+	mux.HandleFunc("POST /auth/login", h.Login)
+	mux.HandleFunc("POST /auth/refresh", h.Refresh)
+	mux.HandleFunc("POST /auth/logout", h.Logout)
+}
+
+type authResponse struct {
+	*model.TokenPair
+	Host *model.Host `json:"host"`
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var body dto.RegisterRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	host := &model.Host{Name: body.Name, Email: body.Email, Role: body.Role}
+	tokens, err := h.svc.Register(r.Context(), host, body.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, authResponse{TokenPair: tokens, Host: host})
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var body dto.LoginRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	host, tokens, err := h.svc.Login(r.Context(), body.Email, body.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, authResponse{TokenPair: tokens, Host: host})
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var body dto.RefreshRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	tokens, err := h.svc.Refresh(r.Context(), body.RefreshToken)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var body dto.RefreshRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := h.svc.Logout(r.Context(), body.RefreshToken); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
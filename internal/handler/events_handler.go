@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 3000
+)
+
+type EventsHandler struct {
+	svc     service.PlaylistService
+	hub     *events.Hub
+	metrics *metrics.Metrics
+}
+
+func NewEventsHandler(svc service.PlaylistService, hub *events.Hub, m *metrics.Metrics) *EventsHandler {
+	return &EventsHandler{
+		svc:     svc,
+		hub:     hub,
+		metrics: m,
+	}
+}
+
+func (h *EventsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /playlists/{id}/events", h.StreamPlaylistEvents)
+}
+
+// StreamPlaylistEvents streams playlist changes as Server-Sent Events.
+func (h *EventsHandler) StreamPlaylistEvents(w http.ResponseWriter, r *http.Request) {
+	playlistID := r.PathValue("id")
+
+	if _, err := h.svc.GetPlaylist(r.Context(), playlistID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var lastEventID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			apierror.Write(w, r, apierror.InvalidParameter("Last-Event-ID"))
+			return
+		}
+		lastEventID = id
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	sub := h.hub.Subscribe(playlistID, lastEventID)
+	defer sub.Unsubscribe()
+	defer h.metrics.Connected(metrics.TransportSSE)()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	if sub.Missed {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeSSE(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -1,23 +1,165 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
+	"github.com/dmarquinah/publist_backend/internal/middleware"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
 	"github.com/dmarquinah/publist_backend/internal/service"
+	"github.com/google/uuid"
 )
 
+const (
+	deviceCookieName   = "publist_device"
+	deviceCookieMaxAge = 365 * 24 * time.Hour
+)
+
+// DefaultRatePolicy limits the routes missing from RatePolicies.
+var DefaultRatePolicy = middleware.RatePolicy{Name: "default", Limit: 120, Window: time.Minute}
+
+// RatePolicies returns the rate limits of the routes that need their own:
+// tight where anonymous clients write, loose where displays poll.
+func RatePolicies() map[string]middleware.RatePolicy {
+	login := middleware.RatePolicy{Name: "auth", Limit: 10, Window: time.Minute}
+	songRequests := middleware.RatePolicy{Name: "song_requests", Limit: 5, Window: time.Minute}
+	votes := middleware.RatePolicy{Name: "votes", Limit: 30, Window: time.Minute}
+	reads := middleware.RatePolicy{Name: "playlist_reads", Limit: 600, Window: time.Minute}
+
+	return map[string]middleware.RatePolicy{
+		"POST /auth/register": login,
+		"POST /auth/login":    login,
+		"POST /auth/refresh":  login,
+
+		"POST /playlists/{id}/requests": songRequests,
+
+		"POST /playlists/{id}/tracks/{trackId}/upvote":   votes,
+		"POST /playlists/{id}/tracks/{trackId}/downvote": votes,
+		"DELETE /playlists/{id}/tracks/{trackId}/vote":   votes,
+
+		"GET /playlists/{id}":         reads,
+		"GET /playlists/{id}/current": reads,
+		"GET /playlists/{id}/tracks":  reads,
+	}
+}
+
 type Handler struct {
-	svc             service.Service
-	playlistHandler *PlaylistHandler
+	svc               service.Service
+	playlistHandler   *PlaylistHandler
+	authHandler       *AuthHandler
+	eventsHandler     *EventsHandler
+	wsHandler         *WebSocketHandler
+	requestHandler    *SongRequestHandler
+	voteHandler       *VoteHandler
+	memberHandler     *MemberHandler
+	moderationHandler *ModerationHandler
 }
 
-func NewHandler(svc service.Service) *Handler {
+func NewHandler(svc service.Service, jwtManager *auth.JWTManager, hub *events.Hub, m *metrics.Metrics) *Handler {
+	authenticate := middleware.Authenticate(jwtManager)
 	return &Handler{
-		svc:             svc,
-		playlistHandler: NewPlaylistHandler(svc),
+		svc:               svc,
+		playlistHandler:   NewPlaylistHandler(svc, authenticate),
+		authHandler:       NewAuthHandler(svc),
+		eventsHandler:     NewEventsHandler(svc, hub, m),
+		wsHandler:         NewWebSocketHandler(svc, hub, m, middleware.OptionalAuthenticate(jwtManager)),
+		requestHandler:    NewSongRequestHandler(svc, authenticate),
+		voteHandler:       NewVoteHandler(svc),
+		memberHandler:     NewMemberHandler(svc, authenticate),
+		moderationHandler: NewModerationHandler(svc, authenticate),
 	}
 }
 
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	h.playlistHandler.RegisterRoutes(mux)
+	h.authHandler.RegisterRoutes(mux)
+	h.eventsHandler.RegisterRoutes(mux)
+	h.wsHandler.RegisterRoutes(mux)
+	h.requestHandler.RegisterRoutes(mux)
+	h.voteHandler.RegisterRoutes(mux)
+	h.memberHandler.RegisterRoutes(mux)
+	h.moderationHandler.RegisterRoutes(mux)
+}
+
+// requireRole authenticates the request and then checks the caller holds at
+// least min, so the claims are always present once the role matches.
+func requireRole(authenticate func(http.Handler) http.Handler, min rbac.Role, next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			apierror.Write(w, r, errorsmsg.ErrUnauthorized)
+			return
+		}
+		if !claims.Role.AtLeast(min) {
+			apierror.Write(w, r, apierror.ErrForbidden)
+			return
+		}
+		next(w, r)
+	})).ServeHTTP
+}
+
+// clientKey identifies an anonymous client by a hash of its address, so
+// limits can be enforced without storing raw IPs. Behind a reverse proxy the
+// address is only the client's when the proxy is in TRUSTED_PROXIES; RealIP
+// leaves the proxy's own address otherwise, and every guest shares a key.
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return hashKey(host)
+}
+
+// deviceKey identifies an anonymous device by a long-lived cookie set on
+// first use, so the ID is always one the server issued.
+func deviceKey(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(deviceCookieName); err == nil && cookie.Value != "" {
+		return hashKey(cookie.Value)
+	}
+
+	id := uuid.New().String()
+	http.SetCookie(w, &http.Cookie{
+		Name:     deviceCookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(deviceCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return hashKey(id)
+}
+
+func hashKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
+
+// listOptions reads the limit, cursor and sort query parameters shared by
+// paginated listings. It fails only if limit isn't a number; the rest is
+// validated by the service.
+func listOptions(r *http.Request) (model.ListOptions, error) {
+	query := r.URL.Query()
+	opts := model.ListOptions{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, errorsmsg.ErrInvalidLimit
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
 }
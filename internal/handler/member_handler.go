@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	"github.com/dmarquinah/publist_backend/internal/dto"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+type MemberHandler struct {
+	svc          service.MemberService
+	authenticate func(http.Handler) http.Handler
+}
+
+func NewMemberHandler(svc service.MemberService, authenticate func(http.Handler) http.Handler) *MemberHandler {
+	return &MemberHandler{
+		svc:          svc,
+		authenticate: authenticate,
+	}
+}
+
+func (h *MemberHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /host/playlists/{id}/members", requireRole(h.authenticate, rbac.RoleViewer, h.GetMembers))
+	mux.HandleFunc("POST /host/playlists/{id}/members", requireRole(h.authenticate, rbac.RoleHost, h.AddMember))
+	mux.HandleFunc("DELETE /host/playlists/{id}/members/{hostId}", requireRole(h.authenticate, rbac.RoleHost, h.RemoveMember))
+}
+
+func (h *MemberHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	members, err := h.svc.GetMembers(r.Context(), playlistID, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, members)
+}
+
+func (h *MemberHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	var body dto.AddMemberRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	member, err := h.svc.AddMember(r.Context(), playlistID, body.Email, body.Role, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, member)
+}
+
+func (h *MemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+	hostID := r.PathValue("hostId")
+
+	if err := h.svc.RemoveMember(r.Context(), playlistID, hostID, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
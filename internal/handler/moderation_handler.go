@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	"github.com/dmarquinah/publist_backend/internal/dto"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+type ModerationHandler struct {
+	svc          service.ModerationService
+	authenticate func(http.Handler) http.Handler
+}
+
+func NewModerationHandler(svc service.ModerationService, authenticate func(http.Handler) http.Handler) *ModerationHandler {
+	return &ModerationHandler{
+		svc:          svc,
+		authenticate: authenticate,
+	}
+}
+
+func (h *ModerationHandler) RegisterRoutes(mux *http.ServeMux) {
+	// Playlist blocklists, run by the playlist's owner and editors
+	mux.HandleFunc("GET /host/playlists/{id}/blocklist", requireRole(h.authenticate, rbac.RoleViewer, h.GetBlockRules))
+	mux.HandleFunc("POST /host/playlists/{id}/blocklist", requireRole(h.authenticate, rbac.RoleStaff, h.CreateBlockRule))
+	mux.HandleFunc("DELETE /host/playlists/{id}/blocklist/{ruleId}", requireRole(h.authenticate, rbac.RoleStaff, h.DeleteBlockRule))
+
+	// Global blocklist, review queue and audit
+	mux.HandleFunc("GET /admin/moderation/rules", requireRole(h.authenticate, rbac.RoleAdmin, h.GetBlockRules))
+	mux.HandleFunc("POST /admin/moderation/rules", requireRole(h.authenticate, rbac.RoleAdmin, h.CreateBlockRule))
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleId}", requireRole(h.authenticate, rbac.RoleAdmin, h.DeleteBlockRule))
+	mux.HandleFunc("GET /admin/moderation/flags", requireRole(h.authenticate, rbac.RoleAdmin, h.GetModerationFlags))
+	mux.HandleFunc("POST /admin/moderation/flags/{flagId}/approve", requireRole(h.authenticate, rbac.RoleAdmin, h.ApproveModerationFlag))
+	mux.HandleFunc("POST /admin/moderation/flags/{flagId}/remove", requireRole(h.authenticate, rbac.RoleAdmin, h.RemoveFlaggedTrack))
+	mux.HandleFunc("GET /admin/moderation/audit", requireRole(h.authenticate, rbac.RoleAdmin, h.GetAuditEntries))
+}
+
+// The blocklist handlers serve both the playlist routes and the global ones,
+// which have no playlist id.
+
+func (h *ModerationHandler) GetBlockRules(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	rules, err := h.svc.GetBlockRules(r.Context(), playlistID, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rules)
+}
+
+func (h *ModerationHandler) CreateBlockRule(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+
+	var body dto.BlockRuleRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rule := body.BlockRule()
+	rule.PlaylistID = r.PathValue("id")
+
+	if err := h.svc.CreateBlockRule(r.Context(), rule, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+func (h *ModerationHandler) DeleteBlockRule(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+	ruleID := r.PathValue("ruleId")
+
+	if err := h.svc.DeleteBlockRule(r.Context(), playlistID, ruleID, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ModerationHandler) GetModerationFlags(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+
+	opts, err := listOptions(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter := model.FlagFilter{
+		Status:     model.FlagStatus(r.URL.Query().Get("status")),
+		PlaylistID: r.URL.Query().Get("playlist_id"),
+	}
+	switch filter.Status {
+	case "", model.FlagPending, model.FlagApproved, model.FlagRemoved:
+	default:
+		apierror.Write(w, r, apierror.InvalidParameter("status"))
+		return
+	}
+
+	page, err := h.svc.GetModerationFlags(r.Context(), filter, opts, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+func (h *ModerationHandler) ApproveModerationFlag(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	flagID := r.PathValue("flagId")
+
+	flag, err := h.svc.ApproveModerationFlag(r.Context(), flagID, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, flag)
+}
+
+func (h *ModerationHandler) RemoveFlaggedTrack(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	flagID := r.PathValue("flagId")
+
+	flag, err := h.svc.RemoveFlaggedTrack(r.Context(), flagID, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, flag)
+}
+
+func (h *ModerationHandler) GetAuditEntries(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+
+	opts, err := listOptions(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter := model.AuditFilter{
+		PlaylistID: r.URL.Query().Get("playlist_id"),
+		ActorID:    r.URL.Query().Get("actor_id"),
+	}
+
+	page, err := h.svc.GetAuditEntries(r.Context(), filter, opts, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
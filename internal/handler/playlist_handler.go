@@ -2,24 +2,27 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
-	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/dmarquinah/publist_backend/internal/apierror"
 	"github.com/dmarquinah/publist_backend/internal/auth"
-	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/dto"
 	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
 	"github.com/dmarquinah/publist_backend/internal/service"
 	"github.com/google/uuid"
 )
 
 type PlaylistHandler struct {
-	svc service.PlaylistService
+	svc          service.PlaylistService
+	authenticate func(http.Handler) http.Handler
 }
 
-func NewPlaylistHandler(svc service.PlaylistService) *PlaylistHandler {
+func NewPlaylistHandler(svc service.PlaylistService, authenticate func(http.Handler) http.Handler) *PlaylistHandler {
 	return &PlaylistHandler{
-		svc: svc,
+		svc:          svc,
+		authenticate: authenticate,
 	}
 }
 
@@ -30,16 +33,23 @@ func (h *PlaylistHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /playlists/{id}/tracks", h.GetPlaylistTracks)
 
 	// Host endpoints
-	/* mux.HandleFunc("POST /host/playlists", h.requireRole("host", h.CreatePlaylist))
-	mux.HandleFunc("PUT /host/playlists/{id}", h.requireRole("host", h.UpdatePlaylist))
-	mux.HandleFunc("DELETE /host/playlists/{id}", h.requireRole("host", h.DeletePlaylist))
-	mux.HandleFunc("GET /host/playlists", h.requireRole("host", h.GetHostPlaylists))
-	mux.HandleFunc("POST /host/playlists/{id}/tracks", h.requireRole("host", h.AddTrack))
-	mux.HandleFunc("DELETE /host/playlists/{id}/tracks/{trackId}", h.requireRole("host", h.RemoveTrack))
-	mux.HandleFunc("PUT /host/playlists/{id}/tracks/{trackId}/position", h.requireRole("host", h.ReorderTrack)) */
+	mux.HandleFunc("POST /host/playlists", h.requireRole(rbac.RoleHost, h.CreatePlaylist))
+	mux.HandleFunc("GET /host/playlists", h.requireRole(rbac.RoleHost, h.GetHostPlaylists))
+
+	// Endpoints shared with playlist editors; the service checks which
+	// playlists the caller may run
+	mux.HandleFunc("PUT /host/playlists/{id}", h.requireRole(rbac.RoleStaff, h.UpdatePlaylist))
+	mux.HandleFunc("DELETE /host/playlists/{id}", h.requireRole(rbac.RoleStaff, h.DeletePlaylist))
+	mux.HandleFunc("POST /host/playlists/{id}/tracks", h.requireRole(rbac.RoleStaff, h.AddTrack))
+	mux.HandleFunc("DELETE /host/playlists/{id}/tracks/{trackId}", h.requireRole(rbac.RoleStaff, h.RemoveTrack))
+	mux.HandleFunc("PUT /host/playlists/{id}/tracks/{trackId}/position", h.requireRole(rbac.RoleStaff, h.ReorderTrack))
+	mux.HandleFunc("POST /host/playlists/{id}/playback/play", h.requireRole(rbac.RoleStaff, h.PlayTrack))
+	mux.HandleFunc("POST /host/playlists/{id}/playback/next", h.requireRole(rbac.RoleStaff, h.SkipTrack))
+	mux.HandleFunc("POST /host/playlists/{id}/playback/previous", h.requireRole(rbac.RoleStaff, h.PreviousTrack))
+	mux.HandleFunc("POST /host/playlists/{id}/playback/stop", h.requireRole(rbac.RoleStaff, h.StopPlayback))
 
 	// Admin endpoints
-	/* mux.HandleFunc("PUT /admin/playlists/{id}/moderate", h.requireRole("admin", h.ModeratePlaylist)) */
+	mux.HandleFunc("PUT /admin/playlists/{id}/moderate", h.requireRole(rbac.RoleAdmin, h.ModeratePlaylist))
 }
 
 func (h *PlaylistHandler) GetPlaylist(w http.ResponseWriter, r *http.Request) {
@@ -47,12 +57,7 @@ func (h *PlaylistHandler) GetPlaylist(w http.ResponseWriter, r *http.Request) {
 
 	playlist, err := h.svc.GetPlaylist(r.Context(), id)
 	if err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -60,26 +65,19 @@ func (h *PlaylistHandler) GetPlaylist(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PlaylistHandler) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 
-	var playlist model.Playlist
-	if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var body dto.PlaylistRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
+	playlist := body.Playlist()
 	playlist.ID = uuid.New().String()
-	playlist.HostID = claims.UserID
-
-	if err := h.svc.CreatePlaylist(r.Context(), &playlist); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrInvalidName):
-			http.Error(w, "Invalid playlist name", http.StatusBadRequest)
-		case errors.Is(err, errorsmsg.ErrNameTooLong):
-			http.Error(w, "Playlist name too long", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+
+	if err := h.svc.CreatePlaylist(r.Context(), playlist, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -87,31 +85,20 @@ func (h *PlaylistHandler) CreatePlaylist(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *PlaylistHandler) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 	id := r.PathValue("id")
 
-	var playlist model.Playlist
-	if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var body dto.PlaylistRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
+	playlist := body.Playlist()
 	playlist.ID = id
-	playlist.HostID = claims.UserID // We set the HostID from the claims
-
-	if err := h.svc.UpdatePlaylist(r.Context(), &playlist); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrUnauthorized):
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		case errors.Is(err, errorsmsg.ErrInvalidName):
-			http.Error(w, "Invalid playlist name", http.StatusBadRequest)
-		case errors.Is(err, errorsmsg.ErrNameTooLong):
-			http.Error(w, "Playlist name too long", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+
+	if err := h.svc.UpdatePlaylist(r.Context(), playlist, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -119,18 +106,11 @@ func (h *PlaylistHandler) UpdatePlaylist(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *PlaylistHandler) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 	id := r.PathValue("id")
 
-	if err := h.svc.DeletePlaylist(r.Context(), id, claims.UserID, claims.Role == "admin"); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrUnauthorized):
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	if err := h.svc.DeletePlaylist(r.Context(), id, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -138,39 +118,49 @@ func (h *PlaylistHandler) DeletePlaylist(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *PlaylistHandler) GetHostPlaylists(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 
-	playlists, err := h.svc.GetPlaylistsByHost(r.Context(), claims.UserID)
+	opts, err := listOptions(r)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
+	var filter model.PlaylistFilter
+	filter.Name = r.URL.Query().Get("name")
+	if value := r.URL.Query().Get("is_moderated"); value != "" {
+		isModerated, err := strconv.ParseBool(value)
+		if err != nil {
+			apierror.Write(w, r, apierror.InvalidParameter("is_moderated"))
+			return
+		}
+		filter.IsModerated = &isModerated
+	}
 
-	respondJSON(w, http.StatusOK, playlists)
+	page, err := h.svc.GetPlaylistsByHost(r.Context(), claims.UserID, filter, opts)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, page)
 }
 
 func (h *PlaylistHandler) AddTrack(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 	playlistID := r.PathValue("id")
 
-	var track model.Playlist_Track
-	if err := json.NewDecoder(r.Body).Decode(&track); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var body dto.AddTrackRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
+	track := body.Track()
 	track.ID = uuid.New().String()
-	track.ID = playlistID
-
-	if err := h.svc.AddTrack(r.Context(), &track, claims.UserID); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrUnauthorized):
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	track.PlaylistID = playlistID
+
+	if err := h.svc.AddTrack(r.Context(), track, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -178,21 +168,12 @@ func (h *PlaylistHandler) AddTrack(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PlaylistHandler) RemoveTrack(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 	playlistID := r.PathValue("id")
 	trackID := r.PathValue("trackId")
 
-	if err := h.svc.RemoveTrack(r.Context(), playlistID, trackID, claims.UserID); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrUnauthorized):
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		case errors.Is(err, errorsmsg.ErrTrackNotFound):
-			http.Error(w, "Track not found", http.StatusNotFound)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	if err := h.svc.RemoveTrack(r.Context(), playlistID, trackID, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -200,31 +181,18 @@ func (h *PlaylistHandler) RemoveTrack(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PlaylistHandler) ReorderTrack(w http.ResponseWriter, r *http.Request) {
-	claims := r.Context().Value("claims").(*auth.Claims)
+	claims, _ := auth.FromContext(r.Context())
 	playlistID := r.PathValue("id")
 	trackID := r.PathValue("trackId")
 
-	var body struct {
-		Position int `json:"position"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	var body dto.ReorderTrackRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if err := h.svc.ReorderTrack(r.Context(), playlistID, trackID, body.Position, claims.UserID); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrUnauthorized):
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		case errors.Is(err, errorsmsg.ErrTrackNotFound):
-			http.Error(w, "Track not found", http.StatusNotFound)
-		case errors.Is(err, errorsmsg.ErrInvalidPosition):
-			http.Error(w, "Invalid position", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	if err := h.svc.ReorderTrack(r.Context(), playlistID, trackID, body.Position, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -236,7 +204,7 @@ func (h *PlaylistHandler) GetCurrentTrack(w http.ResponseWriter, r *http.Request
 
 	track, err := h.svc.GetCurrentTrack(r.Context(), playlistID)
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -251,34 +219,90 @@ func (h *PlaylistHandler) GetCurrentTrack(w http.ResponseWriter, r *http.Request
 func (h *PlaylistHandler) GetPlaylistTracks(w http.ResponseWriter, r *http.Request) {
 	playlistID := r.PathValue("id")
 
-	tracks, err := h.svc.GetPlaylistTracks(r.Context(), playlistID)
+	opts, err := listOptions(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter := model.TrackFilter{
+		Artist: r.URL.Query().Get("artist"),
+		Title:  r.URL.Query().Get("title"),
+	}
+
+	page, err := h.svc.GetPlaylistTracks(r.Context(), playlistID, filter, opts)
 	if err != nil {
-		log.Printf("error: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, tracks)
+	respondJSON(w, http.StatusOK, page)
 }
 
-func (h *PlaylistHandler) ModeratePlaylist(w http.ResponseWriter, r *http.Request) {
+func (h *PlaylistHandler) PlayTrack(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
 	playlistID := r.PathValue("id")
 
-	var body struct {
-		IsModerated bool `json:"is_moderated"`
+	var body dto.PlayTrackRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+
+	track, err := h.svc.PlayTrack(r.Context(), playlistID, body.TrackID, claims.Principal())
+	respondPlayback(w, r, track, err)
+}
+
+func (h *PlaylistHandler) SkipTrack(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	track, err := h.svc.SkipTrack(r.Context(), playlistID, claims.Principal())
+	respondPlayback(w, r, track, err)
+}
+
+func (h *PlaylistHandler) PreviousTrack(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	track, err := h.svc.PreviousTrack(r.Context(), playlistID, claims.Principal())
+	respondPlayback(w, r, track, err)
+}
+
+func (h *PlaylistHandler) StopPlayback(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	err := h.svc.StopPlayback(r.Context(), playlistID, claims.Principal())
+	respondPlayback(w, r, nil, err)
+}
+
+// respondPlayback writes the track now playing, or 204 once playback stopped.
+func respondPlayback(w http.ResponseWriter, r *http.Request, track *model.Playlist_Track, err error) {
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if err := h.svc.ModeratePlaylist(r.Context(), playlistID, body.IsModerated); err != nil {
-		switch {
-		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
-			http.Error(w, "Playlist not found", http.StatusNotFound)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+	if track == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, track)
+}
+
+func (h *PlaylistHandler) ModeratePlaylist(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	var body dto.ModeratePlaylistRequest
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := h.svc.ModeratePlaylist(r.Context(), playlistID, *body.IsModerated, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -286,15 +310,8 @@ func (h *PlaylistHandler) ModeratePlaylist(w http.ResponseWriter, r *http.Reques
 }
 
 // Middleware for role checking
-func (h *PlaylistHandler) requireRole(role string, next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		claims, ok := r.Context().Value("claims").(*auth.Claims)
-		if !ok || claims.Role != role {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-		next(w, r)
-	}
+func (h *PlaylistHandler) requireRole(role rbac.Role, next http.HandlerFunc) http.HandlerFunc {
+	return requireRole(h.authenticate, role, next)
 }
 
 // Helper function to send JSON responses
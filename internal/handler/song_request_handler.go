@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	"github.com/dmarquinah/publist_backend/internal/dto"
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+type SongRequestHandler struct {
+	svc          service.SongRequestService
+	authenticate func(http.Handler) http.Handler
+}
+
+func NewSongRequestHandler(svc service.SongRequestService, authenticate func(http.Handler) http.Handler) *SongRequestHandler {
+	return &SongRequestHandler{
+		svc:          svc,
+		authenticate: authenticate,
+	}
+}
+
+func (h *SongRequestHandler) RegisterRoutes(mux *http.ServeMux) {
+	// Public endpoints
+	mux.HandleFunc("POST /playlists/{id}/requests", h.SubmitSongRequest)
+
+	// Host endpoints
+	mux.HandleFunc("GET /host/playlists/{id}/requests", requireRole(h.authenticate, rbac.RoleViewer, h.GetSongRequests))
+	mux.HandleFunc("POST /host/playlists/{id}/requests/{requestId}/approve", requireRole(h.authenticate, rbac.RoleStaff, h.ApproveSongRequest))
+	mux.HandleFunc("POST /host/playlists/{id}/requests/{requestId}/reject", requireRole(h.authenticate, rbac.RoleStaff, h.RejectSongRequest))
+}
+
+func (h *SongRequestHandler) SubmitSongRequest(w http.ResponseWriter, r *http.Request) {
+	var body dto.SongRequestSubmission
+	if err := dto.Decode(w, r, &body); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	request := body.SongRequest()
+	request.PlaylistID = r.PathValue("id")
+	request.ClientKey = clientKey(r)
+
+	if err := h.svc.SubmitSongRequest(r.Context(), request); err != nil {
+		var retry *errorsmsg.RetryError
+		if errors.As(err, &retry) {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retry.RetryAfter.Seconds())), 10))
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, request)
+}
+
+func (h *SongRequestHandler) GetSongRequests(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+
+	status := model.SongRequestStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", model.SongRequestPending, model.SongRequestApproved, model.SongRequestRejected:
+	default:
+		apierror.Write(w, r, apierror.InvalidParameter("status"))
+		return
+	}
+
+	requests, err := h.svc.GetSongRequests(r.Context(), playlistID, status, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, requests)
+}
+
+func (h *SongRequestHandler) ApproveSongRequest(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+	requestID := r.PathValue("requestId")
+
+	track, err := h.svc.ApproveSongRequest(r.Context(), playlistID, requestID, claims.Principal())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, track)
+}
+
+func (h *SongRequestHandler) RejectSongRequest(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+	playlistID := r.PathValue("id")
+	requestID := r.PathValue("requestId")
+
+	if err := h.svc.RejectSongRequest(r.Context(), playlistID, requestID, claims.Principal()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/service"
+)
+
+type VoteHandler struct {
+	svc service.VoteService
+}
+
+func NewVoteHandler(svc service.VoteService) *VoteHandler {
+	return &VoteHandler{
+		svc: svc,
+	}
+}
+
+func (h *VoteHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /playlists/{id}/tracks/{trackId}/upvote", h.Upvote)
+	mux.HandleFunc("POST /playlists/{id}/tracks/{trackId}/downvote", h.Downvote)
+	mux.HandleFunc("DELETE /playlists/{id}/tracks/{trackId}/vote", h.RemoveVote)
+}
+
+type voteResponse struct {
+	TrackID string `json:"track_id"`
+	Score   int    `json:"score"`
+}
+
+func (h *VoteHandler) Upvote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, 1)
+}
+
+func (h *VoteHandler) Downvote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, -1)
+}
+
+func (h *VoteHandler) vote(w http.ResponseWriter, r *http.Request, value int) {
+	playlistID := r.PathValue("id")
+	trackID := r.PathValue("trackId")
+
+	score, err := h.svc.Vote(r.Context(), playlistID, trackID, deviceKey(w, r), value)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, voteResponse{TrackID: trackID, Score: score})
+}
+
+func (h *VoteHandler) RemoveVote(w http.ResponseWriter, r *http.Request) {
+	playlistID := r.PathValue("id")
+	trackID := r.PathValue("trackId")
+
+	score, err := h.svc.RemoveVote(r.Context(), playlistID, trackID, deviceKey(w, r))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, voteResponse{TrackID: trackID, Score: score})
+}
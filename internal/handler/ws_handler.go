@@ -0,0 +1,422 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/service"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait        = 10 * time.Second
+	wsPongWait         = 60 * time.Second
+	wsPingInterval     = (wsPongWait * 9) / 10
+	wsMaxMessageSize   = 4096
+	wsSendBuffer       = 256
+	wsMaxSubscriptions = 20
+	wsCommandTimeout   = 10 * time.Second
+)
+
+type WebSocketHandler struct {
+	svc          service.PlaylistService
+	hub          *events.Hub
+	metrics      *metrics.Metrics
+	authenticate func(http.Handler) http.Handler
+	upgrader     websocket.Upgrader
+}
+
+func NewWebSocketHandler(svc service.PlaylistService, hub *events.Hub, m *metrics.Metrics, authenticate func(http.Handler) http.Handler) *WebSocketHandler {
+	return &WebSocketHandler{
+		svc:          svc,
+		hub:          hub,
+		metrics:      m,
+		authenticate: authenticate,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Screens are served from other origins, same as the CORS policy
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *WebSocketHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /playlists/{id}/ws", h.authenticate(http.HandlerFunc(h.ServeWebSocket)))
+}
+
+// wsIncoming is a command sent by the client. ID is echoed back in the reply.
+type wsIncoming struct {
+	ID          string   `json:"id,omitempty"`
+	Type        string   `json:"type"`
+	PlaylistID  string   `json:"playlist_id,omitempty"`
+	PlaylistIDs []string `json:"playlist_ids,omitempty"`
+	TrackID     string   `json:"track_id,omitempty"`
+	Position    int      `json:"position,omitempty"`
+	EventID     uint64   `json:"event_id,omitempty"`
+	LastEventID uint64   `json:"last_event_id,omitempty"`
+}
+
+// wsOutgoing is a message pushed to the client.
+type wsOutgoing struct {
+	ID         string        `json:"id,omitempty"`
+	Type       string        `json:"type"`
+	PlaylistID string        `json:"playlist_id,omitempty"`
+	Event      *events.Event `json:"event,omitempty"`
+	Code       string        `json:"code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// ServeWebSocket upgrades the request and subscribes the connection to the
+// playlist in the path. Authenticated hosts may send playlist commands;
+// anonymous connections are read-only.
+func (h *WebSocketHandler) ServeWebSocket(w http.ResponseWriter, r *http.Request) {
+	playlistID := r.PathValue("id")
+
+	if _, err := h.svc.GetPlaylist(r.Context(), playlistID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var lastEventID uint64
+	if v := r.URL.Query().Get("last_event_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			apierror.Write(w, r, apierror.InvalidParameter("last_event_id"))
+			return
+		}
+		lastEventID = id
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied to the client
+		return
+	}
+
+	claims, _ := auth.FromContext(r.Context())
+	session := &wsSession{
+		ctx:    r.Context(),
+		conn:   conn,
+		svc:    h.svc,
+		hub:    h.hub,
+		claims: claims,
+		subs:   make(map[string]*events.Subscription),
+		acked:  make(map[string]uint64),
+		send:   make(chan wsOutgoing, wsSendBuffer),
+		done:   make(chan struct{}),
+	}
+	if err := session.subscribe(playlistID, lastEventID); err != nil {
+		conn.Close()
+		return
+	}
+
+	defer h.metrics.Connected(metrics.TransportWebSocket)()
+
+	go session.writeLoop()
+	session.readLoop()
+}
+
+type wsSession struct {
+	// ctx is the upgraded request's context, which carries its request ID
+	// and logger to the commands run on the connection.
+	ctx    context.Context
+	conn   *websocket.Conn
+	svc    service.PlaylistService
+	hub    *events.Hub
+	claims *auth.Claims // nil for anonymous viewers
+
+	mu    sync.Mutex
+	subs  map[string]*events.Subscription
+	acked map[string]uint64 // highest event ID acknowledged per playlist
+
+	send      chan wsOutgoing
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *wsSession) readLoop() {
+	defer s.close()
+
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var msg wsIncoming
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				s.enqueue(wsOutgoing{Type: "error", Error: "invalid message"})
+				continue
+			}
+			return
+		}
+		s.handle(msg)
+	}
+}
+
+func (s *wsSession) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer func() {
+		ping.Stop()
+		s.close()
+	}()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.hub.Done():
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			s.conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+			return
+		case msg := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *wsSession) handle(msg wsIncoming) {
+	switch msg.Type {
+	case "ping":
+		s.enqueue(wsOutgoing{ID: msg.ID, Type: "pong"})
+	case "ack":
+		s.mu.Lock()
+		if msg.EventID > s.acked[msg.PlaylistID] {
+			s.acked[msg.PlaylistID] = msg.EventID
+		}
+		s.mu.Unlock()
+	case "subscribe":
+		for _, playlistID := range msg.playlists() {
+			if err := s.subscribe(playlistID, msg.LastEventID); err != nil {
+				s.reply(msg, err)
+				return
+			}
+		}
+		s.reply(msg, nil)
+	case "unsubscribe":
+		for _, playlistID := range msg.playlists() {
+			s.unsubscribe(playlistID)
+		}
+		s.reply(msg, nil)
+	case "reorder", "remove_track", "play", "skip", "previous", "stop":
+		s.reply(msg, s.command(msg))
+	default:
+		s.reply(msg, errUnknownMessageType)
+	}
+}
+
+// command runs a host command through the playlist service, which performs
+// the same ownership checks as the REST endpoints.
+func (s *wsSession) command(msg wsIncoming) error {
+	if s.claims == nil {
+		return errorsmsg.ErrUnauthorized
+	}
+	if !s.claims.Role.AtLeast(rbac.RoleStaff) {
+		return errorsmsg.ErrForbidden
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, wsCommandTimeout)
+	defer cancel()
+
+	switch msg.Type {
+	case "reorder":
+		return s.svc.ReorderTrack(ctx, msg.PlaylistID, msg.TrackID, msg.Position, s.claims.Principal())
+	case "remove_track":
+		return s.svc.RemoveTrack(ctx, msg.PlaylistID, msg.TrackID, s.claims.Principal())
+	case "play":
+		_, err := s.svc.PlayTrack(ctx, msg.PlaylistID, msg.TrackID, s.claims.Principal())
+		return err
+	case "skip":
+		_, err := s.svc.SkipTrack(ctx, msg.PlaylistID, s.claims.Principal())
+		return err
+	case "previous":
+		_, err := s.svc.PreviousTrack(ctx, msg.PlaylistID, s.claims.Principal())
+		return err
+	case "stop":
+		return s.svc.StopPlayback(ctx, msg.PlaylistID, s.claims.Principal())
+	}
+	return nil
+}
+
+func (s *wsSession) reply(msg wsIncoming, err error) {
+	if err == nil {
+		s.enqueue(wsOutgoing{ID: msg.ID, Type: "result"})
+		return
+	}
+
+	apiErr := apierror.From(err)
+	s.enqueue(wsOutgoing{ID: msg.ID, Type: "error", Code: apiErr.Code, Error: apiErr.Message})
+}
+
+var (
+	errUnknownMessageType   = apierror.New(http.StatusBadRequest, "unknown_message_type", "Unknown message type")
+	errTooManySubscriptions = apierror.New(http.StatusTooManyRequests, "too_many_subscriptions", "Too many subscriptions")
+)
+
+func (s *wsSession) subscribe(playlistID string, lastEventID uint64) error {
+	s.mu.Lock()
+	add, err := s.canAdd(playlistID)
+	s.mu.Unlock()
+	if !add {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, wsCommandTimeout)
+	defer cancel()
+	if _, err := s.svc.GetPlaylist(ctx, playlistID); err != nil {
+		return err
+	}
+
+	sub := s.hub.Subscribe(playlistID, lastEventID)
+
+	// Check again before inserting: forward may have resubscribed to the
+	// same playlist meanwhile, or close may have unsubscribed everything
+	s.mu.Lock()
+	add, err = s.canAdd(playlistID)
+	if add {
+		s.subs[playlistID] = sub
+	}
+	s.mu.Unlock()
+	if !add {
+		sub.Unsubscribe()
+		return err
+	}
+
+	go s.forward(sub)
+	return nil
+}
+
+// canAdd tells whether a subscription to playlistID is to be added, and if
+// not, whether that is an error. There is nothing to add once the session
+// is closed or already subscribed. The caller holds s.mu.
+func (s *wsSession) canAdd(playlistID string) (bool, error) {
+	select {
+	case <-s.done:
+		return false, nil
+	default:
+	}
+	if _, exists := s.subs[playlistID]; exists {
+		return false, nil
+	}
+	if len(s.subs) >= wsMaxSubscriptions {
+		return false, errTooManySubscriptions
+	}
+	return true, nil
+}
+
+func (s *wsSession) unsubscribe(playlistID string) {
+	s.mu.Lock()
+	sub, ok := s.subs[playlistID]
+	delete(s.subs, playlistID)
+	s.mu.Unlock()
+
+	if ok {
+		sub.Unsubscribe()
+	}
+}
+
+// forward relays a subscription's events to the client until it ends. When
+// the hub drops a lagging subscription, it is resumed from the last event
+// the client acknowledged.
+func (s *wsSession) forward(sub *events.Subscription) {
+	if sub.Missed {
+		s.enqueue(wsOutgoing{Type: "resync", PlaylistID: sub.PlaylistID})
+	}
+	for i := range sub.Replay {
+		if !s.enqueue(wsOutgoing{Type: "event", PlaylistID: sub.PlaylistID, Event: &sub.Replay[i]}) {
+			return
+		}
+	}
+	for event := range sub.C {
+		event := event
+		if !s.enqueue(wsOutgoing{Type: "event", PlaylistID: sub.PlaylistID, Event: &event}) {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	current := s.subs[sub.PlaylistID] == sub
+	if current {
+		delete(s.subs, sub.PlaylistID)
+	}
+	resumeFrom := s.acked[sub.PlaylistID]
+	s.mu.Unlock()
+
+	if !current {
+		return
+	}
+	select {
+	case <-s.done:
+	case <-s.hub.Done():
+	default:
+		if err := s.subscribe(sub.PlaylistID, resumeFrom); err != nil {
+			s.enqueue(wsOutgoing{Type: "unsubscribed", PlaylistID: sub.PlaylistID})
+		}
+	}
+}
+
+// enqueue queues a message for the writer. A client that cannot keep up
+// with its send buffer is disconnected.
+func (s *wsSession) enqueue(msg wsOutgoing) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	select {
+	case s.send <- msg:
+		return true
+	default:
+		s.close()
+		return false
+	}
+}
+
+func (s *wsSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		subs := s.subs
+		s.subs = make(map[string]*events.Subscription)
+		s.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+		s.conn.Close()
+	})
+}
+
+func (m wsIncoming) playlists() []string {
+	if m.PlaylistID != "" {
+		return append([]string{m.PlaylistID}, m.PlaylistIDs...)
+	}
+	return m.PlaylistIDs
+}
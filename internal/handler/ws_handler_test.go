@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/dmarquinah/publist_backend/internal/service"
+	"github.com/gorilla/websocket"
+)
+
+// TestWebSocketConcurrentResume subscribes, acknowledges and has the hub end
+// subscriptions all at once, so that forward resumes subscriptions while
+// the read loop changes them. Run it with -race.
+func TestWebSocketConcurrentResume(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	hub := events.NewHub(events.DefaultReplaySize, events.DefaultRetention)
+	defer hub.Close()
+	svc := service.NewPlaylistService(repo.GetPlaylistRepository(), repo.GetMemberRepository(), repo.GetModerationRepository(), hub, nil, false)
+
+	playlists := []string{"p0", "p1", "p2", "p3"}
+	for _, id := range playlists {
+		if err := repo.CreatePlaylist(context.Background(), &model.Playlist{ID: id, Name: id, HostID: "host"}); err != nil {
+			t.Fatalf("CreatePlaylist: %v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	NewWebSocketHandler(svc, hub, nil, func(next http.Handler) http.Handler { return next }).RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/playlists/p0/ws", nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	// The client reads everything, handing replies to the test
+	replies := make(chan wsOutgoing, 16)
+	go func() {
+		defer close(replies)
+		for {
+			var msg wsOutgoing
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.ID != "" {
+				replies <- msg
+			}
+		}
+	}()
+	// await waits for the reply to the message with the given ID, so the
+	// client doesn't outrun its send buffer
+	await := func(id string) (wsOutgoing, bool) {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case msg, ok := <-replies:
+				if !ok {
+					return wsOutgoing{}, false
+				}
+				if msg.ID == id {
+					return msg, true
+				}
+			case <-timeout:
+				return wsOutgoing{}, false
+			}
+		}
+	}
+
+	const rounds = 200
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(stop)
+		for i := 0; i < rounds; i++ {
+			id := playlists[i%len(playlists)]
+			msgs := []wsIncoming{
+				{Type: "subscribe", PlaylistIDs: playlists},
+				{Type: "unsubscribe", PlaylistID: id},
+				{Type: "subscribe", PlaylistID: id, LastEventID: uint64(i)},
+				{Type: "ack", PlaylistID: id, EventID: uint64(i)},
+				{ID: "round", Type: "ping"},
+			}
+			for _, msg := range msgs {
+				if err := conn.WriteJSON(msg); err != nil {
+					t.Errorf("WriteJSON: %v", err)
+					return
+				}
+			}
+			if _, ok := await("round"); !ok {
+				t.Errorf("round %d: no reply", i)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(100 * time.Microsecond):
+			}
+			id := playlists[i%len(playlists)]
+			hub.Publish(id, events.TrackAdded, i)
+			// Ending the subscription makes forward resume it from the ack
+			hub.Drop(id)
+		}
+	}()
+	wg.Wait()
+
+	// The session survived and still answers
+	if err := conn.WriteJSON(wsIncoming{ID: "ping", Type: "ping"}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if msg, ok := await("ping"); !ok || msg.Type != "pong" {
+		t.Fatalf("got %+v after the concurrent commands, want a pong", msg)
+	}
+	if n := hub.Subscribers(); n > len(playlists) {
+		t.Errorf("hub has %d subscribers, want at most %d", n, len(playlists))
+	}
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/migrations"
+)
+
+// DB checks that the database answers a ping.
+func DB(db *sql.DB) Checker {
+	return CheckerFunc(db.PingContext)
+}
+
+// Migrations checks that every known migration has been applied, so the
+// schema is the one the code expects.
+func Migrations(m *migrations.Migrator) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return fmt.Errorf("reading migration version: %w", err)
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migration(s) pending, from %d_%s", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	})
+}
+
+// Hub checks that the event hub still accepts subscriptions.
+func Hub(h *events.Hub) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		select {
+		case <-h.Done():
+			return errors.New("event hub is closed")
+		default:
+			return nil
+		}
+	})
+}
+
+// Cache checks that the Redis-compatible cache at addr answers a PING.
+func Cache(addr string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+			return err
+		}
+		reply, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if reply = strings.TrimSpace(reply); reply != "+PONG" {
+			return fmt.Errorf("unexpected reply to PING: %q", reply)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,178 @@
+// Package health reports whether the service is alive and ready to serve,
+// from a registry of checks on the components it depends on.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/logging"
+)
+
+// Statuses of the service and of its components.
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Checker checks a component, returning an error if it can't be used.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc lets a function be used as a Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	optional bool
+}
+
+// Registry runs the registered checks to tell whether the service is ready.
+type Registry struct {
+	timeout time.Duration
+
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry returns a registry that gives each check timeout to finish.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a check the service isn't ready without.
+func (r *Registry) Register(name string, c Checker) {
+	r.add(check{name: name, checker: c})
+}
+
+// RegisterOptional adds a check that is reported but doesn't keep the
+// service from being ready, for components it can do without.
+func (r *Registry) RegisterOptional(name string, c Checker) {
+	r.add(check{name: name, checker: c, optional: true})
+}
+
+func (r *Registry) add(c check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, c)
+}
+
+// Shutdown marks the service as no longer ready, so that load balancers
+// stop sending it requests while it drains.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Report is the state of the service and of each of its components.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentReport `json:"components,omitempty"`
+}
+
+type ComponentReport struct {
+	Status     string  `json:"status"`
+	Optional   bool    `json:"optional,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Ready reports whether the service is up.
+func (r Report) Ready() bool {
+	return r.Status == StatusUp
+}
+
+// Check runs every check at once and reports the service up if none of
+// the required ones failed. While shutting down, it reports so without
+// running them.
+func (r *Registry) Check(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+
+	reports := make([]ComponentReport, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = r.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentReport, len(checks))}
+	for i, c := range checks {
+		report.Components[c.name] = reports[i]
+		if reports[i].Status == StatusDown && !c.optional {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run runs a check within the registry's timeout. The reason a check
+// failed is logged rather than reported, as it can reveal internals.
+func (r *Registry) run(ctx context.Context, c check) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+	report := ComponentReport{
+		Status:     StatusUp,
+		Optional:   c.optional,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		report.Status = StatusDown
+		logging.FromContext(ctx).Warn("health check failed",
+			slog.String("component", c.name),
+			slog.Any("err", err),
+		)
+	}
+	return report
+}
+
+// Live answers 200 for as long as the process can serve requests, so that
+// it is only restarted when stuck.
+func (r *Registry) Live() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusUp})
+	})
+}
+
+// Ready answers with the report of every check, and 503 unless the
+// service is up.
+func (r *Registry) Ready() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,43 @@
+// Package logging sets up structured logging and carries the logger of the
+// request being served, so everything logged while serving it can be traced
+// back to it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// Log formats selectable with LOG_FORMAT.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New returns a logger writing to w in format, FormatJSON unless it is
+// FormatText, that drops records below level.
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == FormatText {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// loggerKey is the context key under which the request logger is stored.
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying the given logger.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the default logger if
+// there is none, as outside of requests.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,138 @@
+// Package metrics collects the service's Prometheus metrics. A nil
+// *Metrics records nothing, so code can report to it unconditionally.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "publist"
+
+// Transports of realtime connections.
+const (
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+)
+
+// streamingRoutes stay open for as long as their clients do, so how long
+// they take says nothing about latency. They are counted but not timed;
+// realtime_connections tracks them instead.
+var streamingRoutes = map[string]bool{
+	"/playlists/{id}/events": true,
+	"/playlists/{id}/ws":     true,
+}
+
+type Metrics struct {
+	registry      *prometheus.Registry
+	requests      *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	connections   *prometheus.GaugeVec
+	tracksAdded   prometheus.Counter
+	tracksSkipped prometheus.Counter
+}
+
+// New returns metrics registered along with the Go runtime and process
+// collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "realtime_connections",
+			Help:      "Open realtime connections, by transport.",
+		}, []string{"transport"}),
+		tracksAdded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tracks_added_total",
+			Help:      "Tracks added to playlists.",
+		}),
+		tracksSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tracks_skipped_total",
+			Help:      "Tracks skipped by hosts before they finished playing.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.connections,
+		m.tracksAdded,
+		m.tracksSkipped,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDB reports the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterSubscriptions reports the value of subscriptions, the number of
+// event subscriptions across all playlists.
+func (m *Metrics) RegisterSubscriptions(subscriptions func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_subscriptions",
+		Help:      "Active playlist event subscriptions.",
+	}, func() float64 { return float64(subscriptions()) }))
+}
+
+// ObserveRequest records a served request. route is the pattern it matched,
+// rather than its path, to keep the number of series bounded.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	if !streamingRoutes[route] {
+		m.latency.WithLabelValues(method, route).Observe(duration.Seconds())
+	}
+}
+
+// Connected records a realtime connection opening, and returns the function
+// to call once it closes.
+func (m *Metrics) Connected(transport string) (closed func()) {
+	if m == nil {
+		return func() {}
+	}
+	gauge := m.connections.WithLabelValues(transport)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+func (m *Metrics) TrackAdded() {
+	if m != nil {
+		m.tracksAdded.Inc()
+	}
+}
+
+func (m *Metrics) TrackSkipped() {
+	if m != nil {
+		m.tracksSkipped.Inc()
+	}
+}
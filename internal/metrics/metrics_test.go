@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+)
+
+// series returns the route label of every series of the named metric.
+func series(t *testing.T, m *Metrics, name string) []string {
+	t.Helper()
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var routes []string
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "route" {
+					routes = append(routes, label.GetValue())
+				}
+			}
+		}
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func TestObserveRequestStreaming(t *testing.T) {
+	m := New()
+	for _, route := range []string{"/playlists/{id}", "/playlists/{id}/events", "/playlists/{id}/ws"} {
+		m.ObserveRequest(http.MethodGet, route, http.StatusOK, time.Hour)
+	}
+
+	requests := series(t, m, "publist_http_requests_total")
+	if len(requests) != 3 {
+		t.Errorf("requests counted for %v, want every route", requests)
+	}
+	latency := series(t, m, "publist_http_request_duration_seconds")
+	if len(latency) != 1 || latency[0] != "/playlists/{id}" {
+		t.Errorf("requests timed for %v, want only /playlists/{id}", latency)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveRequest(http.MethodGet, "/playlists/{id}", http.StatusOK, time.Second)
+	m.Connected(TransportSSE)()
+	m.TrackAdded()
+	m.TrackSkipped()
+}
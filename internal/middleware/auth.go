@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+)
+
+// Authenticate validates the bearer token of every request and stores the
+// resulting claims in the request context. Requests without a valid token
+// are rejected with 401.
+func Authenticate(m *auth.JWTManager) func(http.Handler) http.Handler {
+	return authenticate(m, true)
+}
+
+// OptionalAuthenticate behaves like Authenticate but lets anonymous requests
+// through without claims. A token that is present but invalid is still
+// rejected. Clients that cannot set headers, such as browser WebSockets, may
+// pass the token in the access_token query parameter.
+func OptionalAuthenticate(m *auth.JWTManager) func(http.Handler) http.Handler {
+	return authenticate(m, false)
+}
+
+func authenticate(m *auth.JWTManager, required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok && !required {
+				token = r.URL.Query().Get("access_token")
+				ok = token != ""
+				if !ok {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			if !ok {
+				unauthorized(w, r, "", errMissingToken)
+				return
+			}
+
+			claims, err := m.ValidateToken(token)
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrExpiredToken):
+					unauthorized(w, r, "token expired", errExpiredToken)
+				default:
+					unauthorized(w, r, "token invalid", errInvalidToken)
+				}
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), claims)))
+		})
+	}
+}
+
+// StaticToken lets through only the requests bearing token, for endpoints
+// used by machines rather than accounts.
+func StaticToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, r, "", errMissingToken)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				unauthorized(w, r, "token invalid", errInvalidToken)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+var (
+	errMissingToken = apierror.New(http.StatusUnauthorized, "missing_token", "Missing authorization token")
+	errExpiredToken = apierror.New(http.StatusUnauthorized, "token_expired", "Token has expired")
+	errInvalidToken = apierror.New(http.StatusUnauthorized, "invalid_token", "Invalid token")
+)
+
+func unauthorized(w http.ResponseWriter, r *http.Request, description string, err *apierror.Error) {
+	challenge := `Bearer`
+	if description != "" {
+		challenge += ` error="invalid_token", error_description="` + description + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	apierror.Write(w, r, err)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/metrics"
+)
+
+// unmatchedRoute labels the requests no route matched.
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count and latency of the requests to the routes of
+// routes, labeled by the pattern they matched.
+func Metrics(m *metrics.Metrics, routes Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			_, pattern := routes.Handler(r)
+			route := routePath(pattern)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			m.ObserveRequest(r.Method, route, rec.statusCode(), time.Since(start))
+		})
+	}
+}
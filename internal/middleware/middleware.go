@@ -1,15 +1,51 @@
 package middleware
 
 import (
-	"log"
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/logging"
+	"github.com/dmarquinah/publist_backend/internal/requestid"
+	"github.com/google/uuid"
 )
 
+// maxRequestIDLength bounds client-supplied request IDs, which end up in
+// responses and logs.
+const maxRequestIDLength = 128
+
+// Logger gives every request a logger tagged with its ID, stored in the
+// request context, and writes an access log line once it has been served.
+// It must run after RequestID.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Log the request
-		log.Printf("%s %s %s", r.RemoteAddr, r.Method, r.URL)
-		next.ServeHTTP(w, r)
+		start := time.Now()
+		logger := slog.Default().With(
+			slog.String("request_id", requestid.FromContext(r.Context())),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		)
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(logging.NewContext(r.Context(), logger)))
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(r.Context(), level, "request",
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.Int("status", rec.statusCode()),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
 	})
 }
 
@@ -17,19 +53,152 @@ func Recoverer(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic: %v", err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				// The server aborts the response quietly on this one
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				logging.FromContext(r.Context()).Error("panic",
+					slog.Any("panic", err),
+					slog.String("stack", string(debug.Stack())),
+				)
+				apierror.Write(w, r, apierror.ErrInternal)
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
+// responseRecorder notes the status and size of a response for the access
+// log. It passes flushes and hijacks through, for event streams and
+// WebSockets.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer can't be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode is the status sent, 200 if the handler wrote nothing.
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// RequestID gives every request an ID, reusing the X-Request-ID header sent
+// by a proxy or client when it is reasonable, and echoes it in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// RealIP sets the remote address of requests relayed by a trusted proxy to
+// the client address in X-Forwarded-For: the rightmost one that isn't a
+// trusted proxy itself, since clients can put anything on the left.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			remote, err := netip.ParseAddr(host)
+			if err != nil || !isTrusted(remote.Unmap()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break
+				}
+				r.RemoteAddr = net.JoinHostPort(addr.Unmap().String(), "0")
+				if !isTrusted(addr.Unmap()) {
+					break
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
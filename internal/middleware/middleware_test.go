@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"untrusted client", "192.0.2.1:1234", []string{"198.51.100.7"}, "192.0.2.1:1234"},
+		{"no header", "10.0.0.1:1234", nil, "10.0.0.1:1234"},
+		{"one hop", "10.0.0.1:1234", []string{"198.51.100.7"}, "198.51.100.7:0"},
+		{"spoofed leftmost entry", "10.0.0.1:1234", []string{"203.0.113.66, 198.51.100.7"}, "198.51.100.7:0"},
+		{"trusted hops skipped", "10.0.0.1:1234", []string{"203.0.113.66, 198.51.100.7, 10.0.0.2, 10.0.0.3"}, "198.51.100.7:0"},
+		{"several headers", "10.0.0.1:1234", []string{"203.0.113.66", "198.51.100.7, 10.0.0.2"}, "198.51.100.7:0"},
+		{"garbage on the left", "10.0.0.1:1234", []string{"not-an-ip, 198.51.100.7"}, "198.51.100.7:0"},
+		{"garbage on the right", "10.0.0.1:1234", []string{"198.51.100.7, not-an-ip"}, "10.0.0.1:1234"},
+		{"only trusted hops", "10.0.0.1:1234", []string{"10.0.0.2, 10.0.0.3"}, "10.0.0.2:0"},
+		{"mapped IPv4 proxy", "[::ffff:10.0.0.1]:1234", []string{"198.51.100.7"}, "198.51.100.7:0"},
+		{"IPv6 proxy", "[2001:db8::1]:1234", []string{"2001:db8:ffff::1, 2600::7"}, "[2600::7]:0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
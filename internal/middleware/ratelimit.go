@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/apierror"
+	"github.com/dmarquinah/publist_backend/internal/auth"
+)
+
+// sweepInterval is how often idle buckets are looked for and dropped.
+const sweepInterval = time.Minute
+
+var errRateLimited = apierror.New(http.StatusTooManyRequests, "rate_limited", "Too many requests, try again later")
+
+// RatePolicy lets each client make Limit requests in a burst, refilled at
+// Limit per Window. Routes with the same Name share their buckets.
+type RatePolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+func (p RatePolicy) perSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Router finds the pattern a request is routed to, as *http.ServeMux does.
+type Router interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// routePath is the path of a route pattern, without its method.
+func routePath(pattern string) string {
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return path
+	}
+	return pattern
+}
+
+// RateLimiter throttles requests with a token bucket per client and policy.
+// Authenticated clients are told apart by their token's subject, anonymous
+// ones by their address, so run it behind RealIP when behind a proxy.
+type RateLimiter struct {
+	routes   Router
+	policies map[string]RatePolicy
+	fallback RatePolicy
+	tokens   *auth.JWTManager
+	now      func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+// NewRateLimiter limits the routes of routes by the policy of their pattern,
+// or by fallback for the patterns not in policies.
+func NewRateLimiter(routes Router, policies map[string]RatePolicy, fallback RatePolicy, tokens *auth.JWTManager) *RateLimiter {
+	return &RateLimiter{
+		routes:    routes,
+		policies:  policies,
+		fallback:  fallback,
+		tokens:    tokens,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Limit answers 429 once a client has used up its bucket, and reports the
+// state of the bucket in RateLimit-* headers.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := l.fallback
+		if _, pattern := l.routes.Handler(r); pattern != "" {
+			if p, ok := l.policies[pattern]; ok {
+				policy = p
+			}
+		}
+
+		allowed, remaining, reset, retry := l.take(policy, l.client(r))
+
+		header := w.Header()
+		header.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+seconds(policy.Window))
+		header.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("RateLimit-Reset", seconds(reset))
+		if !allowed {
+			header.Set("Retry-After", seconds(retry))
+			apierror.Write(w, r, errRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// client identifies the caller by the subject of a valid bearer token, or
+// else by its address.
+func (l *RateLimiter) client(r *http.Request) string {
+	if token, ok := bearerToken(r); ok {
+		if claims, err := l.tokens.ValidateToken(token); err == nil {
+			return "user:" + claims.UserID
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// take spends a token from the client's bucket, if there is one left. It
+// returns the whole tokens left, how long until the bucket is full again
+// and, if refused, how long until the next token.
+func (l *RateLimiter) take(policy RatePolicy, client string) (allowed bool, remaining int, reset, retry time.Duration) {
+	now := l.now()
+	rate := policy.perSecond()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	key := policy.Name + "|" + client
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), updated: now, window: policy.Window}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(policy.Limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retry = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	reset = time.Duration((float64(policy.Limit) - b.tokens) / rate * float64(time.Second))
+	return allowed, int(b.tokens), reset, retry
+}
+
+// sweep drops the buckets left alone long enough to have refilled, which
+// are no different from new ones. The caller holds l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= b.window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// seconds rounds d up to whole seconds, as the headers expect.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
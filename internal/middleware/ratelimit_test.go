@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+)
+
+var testPolicy = RatePolicy{Name: "test", Limit: 2, Window: 10 * time.Second}
+
+// newTestLimiter returns a limiter of every route by testPolicy, on a clock
+// that only moves when told to.
+func newTestLimiter(t *testing.T) (*RateLimiter, *time.Time, *auth.JWTManager) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /limited", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /other", func(w http.ResponseWriter, r *http.Request) {})
+
+	tokens := auth.NewJWTManager("test-secret", time.Hour)
+	policies := map[string]RatePolicy{"GET /limited": testPolicy}
+	fallback := RatePolicy{Name: "fallback", Limit: 100, Window: time.Second}
+	l := NewRateLimiter(mux, policies, fallback, tokens)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.lastSweep = now
+	return l, &now, tokens
+}
+
+func serve(h http.Handler, path, remoteAddr, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	r.RemoteAddr = remoteAddr
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l, now, _ := newTestLimiter(t)
+	h := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Two tokens refilled at one per 5s; headers round up to whole seconds
+	steps := []struct {
+		advance    time.Duration
+		status     int
+		remaining  string
+		reset      string
+		retryAfter string
+	}{
+		{0, http.StatusOK, "1", "5", ""},
+		{0, http.StatusOK, "0", "10", ""},
+		{0, http.StatusTooManyRequests, "0", "10", "5"},
+		{2500 * time.Millisecond, http.StatusTooManyRequests, "0", "8", "3"},
+		{2500 * time.Millisecond, http.StatusOK, "0", "10", ""},
+		{time.Minute, http.StatusOK, "1", "5", ""},
+	}
+	for i, step := range steps {
+		*now = now.Add(step.advance)
+		w := serve(h, "/limited", "192.0.2.1:1234", "")
+
+		if w.Code != step.status {
+			t.Errorf("step %d: status = %d, want %d", i, w.Code, step.status)
+		}
+		header := w.Header()
+		if got := header.Get("RateLimit-Policy"); got != "2;w=10" {
+			t.Errorf("step %d: RateLimit-Policy = %q, want %q", i, got, "2;w=10")
+		}
+		if got := header.Get("RateLimit-Remaining"); got != step.remaining {
+			t.Errorf("step %d: RateLimit-Remaining = %q, want %q", i, got, step.remaining)
+		}
+		if got := header.Get("RateLimit-Reset"); got != step.reset {
+			t.Errorf("step %d: RateLimit-Reset = %q, want %q", i, got, step.reset)
+		}
+		if got := header.Get("Retry-After"); got != step.retryAfter {
+			t.Errorf("step %d: Retry-After = %q, want %q", i, got, step.retryAfter)
+		}
+	}
+}
+
+func TestRateLimiterClients(t *testing.T) {
+	l, _, tokens := newTestLimiter(t)
+	h := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	token, err := tokens.GenerateToken("user-1", rbac.RoleStaff)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	other := auth.NewJWTManager("other-secret", time.Hour)
+	forged, err := other.GenerateToken("user-1", rbac.RoleStaff)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		token      string
+		status     int
+	}{
+		// The user's bucket follows them across addresses
+		{"user first", "/limited", "192.0.2.1:1", token, http.StatusOK},
+		{"user second address", "/limited", "192.0.2.2:1", token, http.StatusOK},
+		{"user exhausted", "/limited", "192.0.2.3:1", token, http.StatusTooManyRequests},
+		// Anonymous clients, and invalid tokens, are told apart by address
+		{"anonymous", "/limited", "192.0.2.1:2", "", http.StatusOK},
+		{"invalid token", "/limited", "192.0.2.1:3", forged, http.StatusOK},
+		{"anonymous exhausted", "/limited", "192.0.2.1:4", "", http.StatusTooManyRequests},
+		{"other address", "/limited", "192.0.2.9:1", "", http.StatusOK},
+		// Routes without a policy of their own use the fallback
+		{"fallback route", "/other", "192.0.2.1:5", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(h, tt.path, tt.remoteAddr, tt.token)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	l, now, _ := newTestLimiter(t)
+	h := l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve(h, "/limited", "192.0.2.1:1", "")
+	*now = now.Add(55 * time.Second)
+	serve(h, "/limited", "192.0.2.2:1", "")
+	if len(l.buckets) != 2 {
+		t.Fatalf("got %d buckets before the sweep, want 2", len(l.buckets))
+	}
+
+	// The first bucket has refilled and is dropped, the second is kept
+	*now = now.Add(6 * time.Second)
+	serve(h, "/limited", "192.0.2.3:1", "")
+	want := map[string]bool{"test|ip:192.0.2.2": true, "test|ip:192.0.2.3": true}
+	if len(l.buckets) != len(want) {
+		t.Errorf("got %d buckets after the sweep, want %d", len(l.buckets), len(want))
+	}
+	for key := range want {
+		if _, ok := l.buckets[key]; !ok {
+			t.Errorf("bucket %q was dropped", key)
+		}
+	}
+}
+
+func TestRateLimiterBehindRealIP(t *testing.T) {
+	l, _, _ := newTestLimiter(t)
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	h := RealIP(trusted)(l.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	// A client sending its own X-Forwarded-For can't get a fresh bucket
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("X-Forwarded-For", "198.51.100."+strconv.Itoa(i))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		want := http.StatusOK
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Errorf("request %d: status = %d, want %d", i, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dmarquinah/publist_backend/internal/tracing"
+)
+
+// Tracing starts a server span for every request to the routes of routes,
+// named after the pattern it matched and continuing the trace in the
+// request's W3C traceparent header, if any.
+func Tracing(routes Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			name := r.Method
+			_, pattern := routes.Handler(r)
+			if pattern != "" {
+				name = pattern
+			}
+			ctx, span := tracing.Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.HTTPRoute(routePath(pattern)),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			status := rec.statusCode()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			// Client errors are the client's; only server errors fail the span
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// lockName is the advisory lock held while migrating, so that several
+// instances starting together don't apply the same migration twice.
+const lockName = "publist_schema_migrations"
+
+// lockKey is lockName's key for Postgres, whose advisory locks take an
+// integer.
+const lockKey = 7364918265
+
+// dialect holds the bookkeeping statements that differ between databases.
+// Each driver also has its own directory of migrations.
+type dialect struct {
+	lock        string
+	unlock      string
+	createTable string
+	insert      string
+	delete      string
+	// transactional is set where DDL can be rolled back, so a migration
+	// and its schema_migrations row are applied in one transaction.
+	transactional bool
+}
+
+var dialects = map[string]dialect{
+	// MySQL commits implicitly after every DDL statement, so a migration
+	// failing halfway leaves its earlier statements applied and the
+	// migration unrecorded. Undo those statements by hand before running
+	// it again.
+	"mysql": {
+		lock:   fmt.Sprintf("SELECT GET_LOCK('%s', 60)", lockName),
+		unlock: fmt.Sprintf("SELECT RELEASE_LOCK('%s')", lockName),
+		createTable: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    BIGINT       NOT NULL PRIMARY KEY,
+				name       VARCHAR(255) NOT NULL,
+				applied_at DATETIME(6)  NOT NULL
+			)
+		`,
+		insert: "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		delete: "DELETE FROM schema_migrations WHERE version = ?",
+	},
+	"postgres": {
+		// pg_advisory_lock waits until ctx is done and returns no value, so
+		// select a constant to share the "1 means locked" check
+		lock:   fmt.Sprintf("SELECT 1 FROM (SELECT pg_advisory_lock(%d)) l", lockKey),
+		unlock: fmt.Sprintf("SELECT pg_advisory_unlock(%d)", lockKey),
+		createTable: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    BIGINT       NOT NULL PRIMARY KEY,
+				name       VARCHAR(255) NOT NULL,
+				applied_at TIMESTAMPTZ  NOT NULL
+			)
+		`,
+		insert:        "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+		delete:        "DELETE FROM schema_migrations WHERE version = $1",
+		transactional: true,
+	},
+	"sqlite": {
+		// SQLite has no advisory locks. The database belongs to a single
+		// process, and concurrent writers wait on the busy timeout.
+		lock:   "SELECT 1",
+		unlock: "SELECT 1",
+		createTable: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INTEGER  NOT NULL PRIMARY KEY,
+				name       TEXT     NOT NULL,
+				applied_at DATETIME NOT NULL
+			)
+		`,
+		insert:        "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		delete:        "DELETE FROM schema_migrations WHERE version = ?",
+		transactional: true,
+	},
+}
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+// New loads the embedded migrations for driver ("mysql", "postgres" or
+// "sqlite"). Files are named <version>_<name>.up.sql and
+// <version>_<name>.down.sql.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	migrations, err := load(files, driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, label, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s: missing name", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", name, err)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("reading migration %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := m.run(ctx, conn, migration.Up, m.dialect.insert, migration.Version, migration.Name, time.Now())
+			if err != nil {
+				return fmt.Errorf("applying %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the given number of most recently applied migrations and
+// returns the ones reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+			if err := m.run(ctx, conn, migration.Down, m.dialect.delete, migration.Version); err != nil {
+				return fmt.Errorf("reverting %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied, if any.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := done[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the known migrations not yet applied. Unlike Status, it
+// only reads, so it fails if the schema_migrations table is missing.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := done[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// withLock runs fn on a dedicated connection while holding the migration lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, m.dialect.lock).Scan(&locked); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return fmt.Errorf("acquiring migration lock: timed out")
+	}
+	defer conn.ExecContext(context.Background(), m.dialect.unlock)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, m.dialect.createTable)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// run executes a migration script and then the record statement that
+// updates schema_migrations. Where the dialect is transactional both run in
+// one transaction, so a failing statement leaves the schema as it was.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	if !m.dialect.transactional {
+		if err := execScript(ctx, conn, script); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, record, args...); err != nil {
+			return fmt.Errorf("recording: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := execScript(ctx, tx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("recording: %w", err)
+	}
+	return tx.Commit()
+}
+
+// execer is a connection or a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// execScript runs each statement of a script in turn. Statements are split
+// on semicolons ending a line, so they must not contain such a semicolon
+// inside a string literal.
+func execScript(ctx context.Context, db execer, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
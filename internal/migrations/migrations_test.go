@@ -0,0 +1,117 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/config"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := config.NewDB(&config.DBConfig{
+		Driver:      config.DriverSQLite,
+		Path:        filepath.Join(t.TempDir(), "publist.db"),
+		BusyTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tables lists the tables in a SQLite database, schema_migrations aside.
+func tables(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')
+		ORDER BY name`)
+	if err != nil {
+		t.Fatalf("listing tables: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("listing tables: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestSQLiteUpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m, err := New(db, config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Twice, so every down script leaves a schema the up scripts accept
+	for round := 0; round < 2; round++ {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		if len(applied) != len(m.migrations) {
+			t.Fatalf("Up applied %d migrations, want %d", len(applied), len(m.migrations))
+		}
+		if pending, err := m.Pending(ctx); err != nil || len(pending) != 0 {
+			t.Fatalf("Pending after Up = %d, %v; want none", len(pending), err)
+		}
+		if len(tables(t, db)) == 0 {
+			t.Fatal("no tables after Up")
+		}
+
+		// One step at a time, so each down script runs against the schema
+		// its own up script left
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			reverted, err := m.Down(ctx, 1)
+			if err != nil {
+				t.Fatalf("Down: %v", err)
+			}
+			if len(reverted) != 1 || reverted[0].Version != m.migrations[i].Version {
+				t.Fatalf("Down reverted %+v, want %d_%s", reverted, m.migrations[i].Version, m.migrations[i].Name)
+			}
+		}
+		if names := tables(t, db); len(names) != 0 {
+			t.Errorf("tables left after reverting everything: %v", names)
+		}
+		if pending, err := m.Pending(ctx); err != nil || len(pending) != len(m.migrations) {
+			t.Fatalf("Pending after Down = %d, %v; want %d", len(pending), err, len(m.migrations))
+		}
+	}
+}
+
+func TestSQLiteFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	m := &Migrator{db: db, dialect: dialects[config.DriverSQLite], migrations: []Migration{
+		{Version: 1, Name: "good", Up: "CREATE TABLE a (id INTEGER);", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "bad", Up: "CREATE TABLE b (id INTEGER);\nCREATE TABLE a (id INTEGER);"},
+	}}
+
+	applied, err := m.Up(ctx)
+	if err == nil {
+		t.Fatal("Up succeeded with a failing migration")
+	}
+	if len(applied) != 1 {
+		t.Errorf("Up applied %d migrations, want 1", len(applied))
+	}
+
+	// The failing migration's first statement was rolled back with it
+	if names := tables(t, db); len(names) != 1 || names[0] != "a" {
+		t.Errorf("tables = %v, want [a]", names)
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil || len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("Pending = %+v, %v; want 2_bad", pending, err)
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE hosts (
+    id            CHAR(36)     NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at    DATETIME(6)  NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_hosts_email (email)
+);
@@ -0,0 +1,39 @@
+CREATE TABLE playlists (
+    id           CHAR(36)     NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    host_id      CHAR(36)     NOT NULL,
+    created_at   DATETIME(6)  NOT NULL,
+    updated_at   DATETIME(6)  NOT NULL,
+    is_moderated BOOLEAN      NOT NULL DEFAULT FALSE,
+    vote_ordered BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    KEY idx_playlists_host (host_id),
+    CONSTRAINT fk_playlists_host FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
+
+CREATE TABLE tracks (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    artist      VARCHAR(255) NOT NULL DEFAULT '',
+    duration    INT          NOT NULL DEFAULT 0,
+    position    INT          NOT NULL,
+    added_at    DATETIME(6)  NOT NULL,
+    is_playing  BOOLEAN      NOT NULL DEFAULT FALSE,
+    started_at  DATETIME(6)  NULL,
+    PRIMARY KEY (id),
+    KEY idx_tracks_playlist_position (playlist_id, position),
+    CONSTRAINT fk_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+CREATE TABLE playlist_tracks (
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    position    INT         NOT NULL,
+    added_at    DATETIME(6) NOT NULL,
+    is_playing  BOOLEAN     NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (playlist_id, track_id),
+    KEY idx_playlist_tracks_position (playlist_id, position),
+    CONSTRAINT fk_playlist_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_tracks_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+CREATE TABLE refresh_tokens (
+    id          CHAR(36)    NOT NULL,
+    host_id     CHAR(36)    NOT NULL,
+    family_id   CHAR(36)    NOT NULL,
+    token_hash  CHAR(64)    NOT NULL,
+    created_at  DATETIME(6) NOT NULL,
+    expires_at  DATETIME(6) NOT NULL,
+    revoked_at  DATETIME(6) NULL,
+    replaced_by CHAR(36)    NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_refresh_tokens_hash (token_hash),
+    KEY idx_refresh_tokens_family (family_id),
+    CONSTRAINT fk_refresh_tokens_host FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
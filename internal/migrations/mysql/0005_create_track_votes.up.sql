@@ -0,0 +1,9 @@
+CREATE TABLE track_votes (
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    voter_key   CHAR(32)    NOT NULL,
+    value       TINYINT     NOT NULL,
+    created_at  DATETIME(6) NOT NULL,
+    PRIMARY KEY (playlist_id, track_id, voter_key),
+    CONSTRAINT fk_track_votes_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,47 @@
+-- Every playlist entry becomes a track row of its own again
+ALTER TABLE track_votes DROP FOREIGN KEY fk_track_votes_entry;
+
+CREATE TABLE tracks_old (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    artist      VARCHAR(255) NOT NULL DEFAULT '',
+    duration    INT          NOT NULL DEFAULT 0,
+    position    INT          NOT NULL,
+    added_at    DATETIME(6)  NOT NULL,
+    is_playing  BOOLEAN      NOT NULL DEFAULT FALSE,
+    started_at  DATETIME(6)  NULL,
+    PRIMARY KEY (id),
+    KEY idx_tracks_playlist_position (playlist_id, position)
+);
+
+INSERT INTO tracks_old (id, playlist_id, title, artist, duration, position, added_at, is_playing, started_at)
+SELECT pt.id, pt.playlist_id, t.title, t.artist, t.duration, pt.position, pt.added_at, pt.is_playing, pt.started_at
+FROM playlist_tracks pt
+INNER JOIN tracks t ON t.id = pt.track_id;
+
+DROP TABLE playlist_tracks;
+DROP TABLE tracks;
+RENAME TABLE tracks_old TO tracks;
+
+ALTER TABLE tracks
+    ADD CONSTRAINT fk_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE;
+
+CREATE TABLE playlist_tracks (
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    position    INT         NOT NULL,
+    added_at    DATETIME(6) NOT NULL,
+    is_playing  BOOLEAN     NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (playlist_id, track_id),
+    KEY idx_playlist_tracks_position (playlist_id, position),
+    CONSTRAINT fk_playlist_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_tracks_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE
+);
+
+INSERT INTO playlist_tracks (playlist_id, track_id, position, added_at, is_playing)
+SELECT playlist_id, id, position, added_at, is_playing
+FROM tracks;
+
+ALTER TABLE track_votes
+    ADD CONSTRAINT fk_track_votes_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE;
@@ -0,0 +1,71 @@
+-- Tracks become a reusable catalog; playlist membership, order and playback
+-- state move to playlist_tracks, keyed by an entry ID. Rows written to
+-- tracks so far keep their ID as the entry ID, so votes and song requests
+-- referencing them stay valid.
+ALTER TABLE track_votes DROP FOREIGN KEY fk_track_votes_track;
+
+CREATE TABLE playlist_tracks_new (
+    id          CHAR(36)    NOT NULL,
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    position    INT         NOT NULL,
+    added_at    DATETIME(6) NOT NULL,
+    is_playing  BOOLEAN     NOT NULL DEFAULT FALSE,
+    started_at  DATETIME(6) NULL,
+    PRIMARY KEY (id),
+    KEY idx_playlist_tracks_position (playlist_id, position),
+    KEY idx_playlist_tracks_track (track_id)
+);
+
+INSERT INTO playlist_tracks_new (id, playlist_id, track_id, position, added_at, is_playing, started_at)
+SELECT id, playlist_id, id, position, added_at, is_playing, started_at
+FROM tracks;
+
+INSERT INTO playlist_tracks_new (id, playlist_id, track_id, position, added_at, is_playing, started_at)
+SELECT UUID(), pt.playlist_id, pt.track_id, pt.position, pt.added_at, pt.is_playing, NULL
+FROM playlist_tracks pt
+WHERE NOT EXISTS (
+    SELECT 1 FROM playlist_tracks_new n
+    WHERE n.playlist_id = pt.playlist_id AND n.track_id = pt.track_id
+);
+
+-- Merging both sources can leave gaps or duplicates, so renumber 1..n
+UPDATE playlist_tracks_new n
+INNER JOIN (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY playlist_id ORDER BY position, added_at, id) AS rn
+    FROM playlist_tracks_new
+) ordered ON ordered.id = n.id
+SET n.position = ordered.rn;
+
+-- Keep at most one playing track per playlist
+UPDATE playlist_tracks_new n
+INNER JOIN (
+    SELECT playlist_id, MIN(position) AS position
+    FROM playlist_tracks_new
+    WHERE is_playing = TRUE
+    GROUP BY playlist_id
+) playing ON playing.playlist_id = n.playlist_id
+SET n.is_playing = (n.position = playing.position),
+    n.started_at = IF(n.position = playing.position, n.started_at, NULL);
+
+DROP TABLE playlist_tracks;
+RENAME TABLE playlist_tracks_new TO playlist_tracks;
+
+ALTER TABLE tracks
+    DROP FOREIGN KEY fk_tracks_playlist,
+    DROP INDEX idx_tracks_playlist_position,
+    DROP COLUMN playlist_id,
+    DROP COLUMN position,
+    DROP COLUMN is_playing,
+    DROP COLUMN started_at,
+    RENAME COLUMN added_at TO created_at;
+
+ALTER TABLE playlist_tracks
+    ADD CONSTRAINT fk_playlist_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_playlist_tracks_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE;
+
+DELETE FROM track_votes
+WHERE NOT EXISTS (SELECT 1 FROM playlist_tracks pt WHERE pt.id = track_votes.track_id);
+
+ALTER TABLE track_votes
+    ADD CONSTRAINT fk_track_votes_entry FOREIGN KEY (track_id) REFERENCES playlist_tracks (id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- Existing accounts all registered as hosts
+ALTER TABLE hosts ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'host';
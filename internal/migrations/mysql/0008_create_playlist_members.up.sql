@@ -0,0 +1,14 @@
+CREATE TABLE playlist_members (
+    playlist_id CHAR(36)    NOT NULL,
+    host_id     CHAR(36)    NOT NULL,
+    role        VARCHAR(16) NOT NULL,
+    added_at    DATETIME(6) NOT NULL,
+    PRIMARY KEY (playlist_id, host_id),
+    KEY idx_playlist_members_host (host_id),
+    CONSTRAINT fk_playlist_members_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_members_host FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
+
+-- Playlists so far belong to the host who created them
+INSERT INTO playlist_members (playlist_id, host_id, role, added_at)
+SELECT id, host_id, 'owner', created_at FROM playlists;
@@ -0,0 +1,47 @@
+-- Global rules have no playlist
+CREATE TABLE block_rules (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NULL,
+    kind        VARCHAR(16)  NOT NULL,
+    pattern     VARCHAR(255) NOT NULL,
+    action      VARCHAR(16)  NOT NULL,
+    created_by  CHAR(36)     NOT NULL,
+    created_at  DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_block_rules_playlist (playlist_id, created_at),
+    CONSTRAINT fk_block_rules_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+CREATE TABLE moderation_flags (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NOT NULL,
+    entry_id    CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    artist      VARCHAR(255) NOT NULL DEFAULT '',
+    rule_id     CHAR(36)     NOT NULL,
+    kind        VARCHAR(16)  NOT NULL,
+    pattern     VARCHAR(255) NOT NULL,
+    status      VARCHAR(16)  NOT NULL,
+    created_at  DATETIME(6)  NOT NULL,
+    reviewed_by CHAR(36)     NULL,
+    reviewed_at DATETIME(6)  NULL,
+    PRIMARY KEY (id),
+    KEY idx_moderation_flags_status (status, created_at),
+    KEY idx_moderation_flags_playlist (playlist_id, created_at),
+    CONSTRAINT fk_moderation_flags_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+-- The audit outlives the accounts, playlists and rules it mentions
+CREATE TABLE moderation_audit (
+    id          CHAR(36)     NOT NULL,
+    actor_id    CHAR(36)     NULL,
+    action      VARCHAR(32)  NOT NULL,
+    playlist_id CHAR(36)     NULL,
+    target_id   CHAR(36)     NULL,
+    detail      VARCHAR(512) NOT NULL DEFAULT '',
+    created_at  DATETIME(6)  NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_moderation_audit_created (created_at),
+    KEY idx_moderation_audit_playlist (playlist_id, created_at),
+    KEY idx_moderation_audit_actor (actor_id, created_at)
+);
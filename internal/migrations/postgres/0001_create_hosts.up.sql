@@ -0,0 +1,10 @@
+CREATE TABLE hosts (
+    id            CHAR(36)     NOT NULL,
+    name          VARCHAR(255) NOT NULL,
+    email         VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_hosts_email UNIQUE (email)
+);
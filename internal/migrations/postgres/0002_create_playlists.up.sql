@@ -0,0 +1,40 @@
+-- Postgres support started after tracks were split into a catalog, so the
+-- tables are created in their current shape and 0006 has nothing to do.
+CREATE TABLE playlists (
+    id           CHAR(36)     NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    host_id      CHAR(36)     NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL,
+    is_moderated BOOLEAN      NOT NULL DEFAULT FALSE,
+    vote_ordered BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_playlists_host FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_playlists_host ON playlists (host_id);
+
+CREATE TABLE tracks (
+    id         CHAR(36)     NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    artist     VARCHAR(255) NOT NULL DEFAULT '',
+    duration   INT          NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE playlist_tracks (
+    id          CHAR(36)    NOT NULL,
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    position    INT         NOT NULL,
+    added_at    TIMESTAMPTZ NOT NULL,
+    is_playing  BOOLEAN     NOT NULL DEFAULT FALSE,
+    started_at  TIMESTAMPTZ NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_playlist_tracks_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_tracks_track FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_playlist_tracks_position ON playlist_tracks (playlist_id, position);
+CREATE INDEX idx_playlist_tracks_track ON playlist_tracks (track_id);
@@ -0,0 +1,15 @@
+CREATE TABLE refresh_tokens (
+    id          CHAR(36)    NOT NULL,
+    host_id     CHAR(36)    NOT NULL,
+    family_id   CHAR(36)    NOT NULL,
+    token_hash  CHAR(64)    NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ NULL,
+    replaced_by CHAR(36)    NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uq_refresh_tokens_hash UNIQUE (token_hash),
+    CONSTRAINT fk_refresh_tokens_host FOREIGN KEY (host_id) REFERENCES hosts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
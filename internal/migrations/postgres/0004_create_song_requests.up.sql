@@ -0,0 +1,18 @@
+CREATE TABLE song_requests (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    artist      VARCHAR(255) NOT NULL DEFAULT '',
+    duration    INT          NOT NULL DEFAULT 0,
+    nickname    VARCHAR(50)  NOT NULL,
+    client_key  CHAR(32)     NOT NULL,
+    status      VARCHAR(16)  NOT NULL,
+    track_id    CHAR(36)     NULL,
+    created_at  TIMESTAMPTZ  NOT NULL,
+    reviewed_at TIMESTAMPTZ  NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_song_requests_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_song_requests_status ON song_requests (playlist_id, status, created_at);
+CREATE INDEX idx_song_requests_client ON song_requests (playlist_id, client_key, created_at);
@@ -0,0 +1,9 @@
+CREATE TABLE track_votes (
+    playlist_id CHAR(36)    NOT NULL,
+    track_id    CHAR(36)    NOT NULL,
+    voter_key   CHAR(32)    NOT NULL,
+    value       SMALLINT    NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (playlist_id, track_id, voter_key),
+    CONSTRAINT fk_track_votes_entry FOREIGN KEY (track_id) REFERENCES playlist_tracks (id) ON DELETE CASCADE
+);
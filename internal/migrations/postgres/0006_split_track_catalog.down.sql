@@ -0,0 +1 @@
+-- Nothing to do: 0002 already creates the catalog and playlist_tracks.
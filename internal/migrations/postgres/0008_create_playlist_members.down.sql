@@ -0,0 +1 @@
+DROP TABLE playlist_members;
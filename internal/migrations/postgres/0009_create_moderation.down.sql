@@ -0,0 +1,5 @@
+DROP TABLE moderation_audit;
+
+DROP TABLE moderation_flags;
+
+DROP TABLE block_rules;
@@ -0,0 +1,50 @@
+-- Global rules have no playlist
+CREATE TABLE block_rules (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NULL,
+    kind        VARCHAR(16)  NOT NULL,
+    pattern     VARCHAR(255) NOT NULL,
+    action      VARCHAR(16)  NOT NULL,
+    created_by  CHAR(36)     NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_block_rules_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_block_rules_playlist ON block_rules (playlist_id, created_at);
+
+CREATE TABLE moderation_flags (
+    id          CHAR(36)     NOT NULL,
+    playlist_id CHAR(36)     NOT NULL,
+    entry_id    CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    artist      VARCHAR(255) NOT NULL DEFAULT '',
+    rule_id     CHAR(36)     NOT NULL,
+    kind        VARCHAR(16)  NOT NULL,
+    pattern     VARCHAR(255) NOT NULL,
+    status      VARCHAR(16)  NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL,
+    reviewed_by CHAR(36)     NULL,
+    reviewed_at TIMESTAMPTZ  NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_moderation_flags_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_moderation_flags_status ON moderation_flags (status, created_at);
+CREATE INDEX idx_moderation_flags_playlist ON moderation_flags (playlist_id, created_at);
+
+-- The audit outlives the accounts, playlists and rules it mentions
+CREATE TABLE moderation_audit (
+    id          CHAR(36)     NOT NULL,
+    actor_id    CHAR(36)     NULL,
+    action      VARCHAR(32)  NOT NULL,
+    playlist_id CHAR(36)     NULL,
+    target_id   CHAR(36)     NULL,
+    detail      VARCHAR(512) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX idx_moderation_audit_created ON moderation_audit (created_at);
+CREATE INDEX idx_moderation_audit_playlist ON moderation_audit (playlist_id, created_at);
+CREATE INDEX idx_moderation_audit_actor ON moderation_audit (actor_id, created_at);
@@ -0,0 +1,9 @@
+CREATE TABLE hosts (
+    id            TEXT     NOT NULL PRIMARY KEY,
+    name          TEXT     NOT NULL,
+    email         TEXT     NOT NULL,
+    password_hash TEXT     NOT NULL,
+    created_at    DATETIME NOT NULL,
+    is_active     BOOLEAN  NOT NULL DEFAULT TRUE,
+    CONSTRAINT uq_hosts_email UNIQUE (email)
+);
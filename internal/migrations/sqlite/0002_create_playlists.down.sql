@@ -0,0 +1,3 @@
+DROP TABLE playlist_tracks;
+DROP TABLE tracks;
+DROP TABLE playlists;
@@ -0,0 +1,34 @@
+-- SQLite support started after tracks were split into a catalog, so the
+-- tables are created in their current shape and 0006 has nothing to do.
+CREATE TABLE playlists (
+    id           TEXT     NOT NULL PRIMARY KEY,
+    name         TEXT     NOT NULL,
+    host_id      TEXT     NOT NULL REFERENCES hosts (id) ON DELETE CASCADE,
+    created_at   DATETIME NOT NULL,
+    updated_at   DATETIME NOT NULL,
+    is_moderated BOOLEAN  NOT NULL DEFAULT FALSE,
+    vote_ordered BOOLEAN  NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_playlists_host ON playlists (host_id);
+
+CREATE TABLE tracks (
+    id         TEXT     NOT NULL PRIMARY KEY,
+    title      TEXT     NOT NULL,
+    artist     TEXT     NOT NULL DEFAULT '',
+    duration   INTEGER  NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE playlist_tracks (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    playlist_id TEXT     NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    track_id    TEXT     NOT NULL REFERENCES tracks (id) ON DELETE CASCADE,
+    position    INTEGER  NOT NULL,
+    added_at    DATETIME NOT NULL,
+    is_playing  BOOLEAN  NOT NULL DEFAULT FALSE,
+    started_at  DATETIME NULL
+);
+
+CREATE INDEX idx_playlist_tracks_position ON playlist_tracks (playlist_id, position);
+CREATE INDEX idx_playlist_tracks_track ON playlist_tracks (track_id);
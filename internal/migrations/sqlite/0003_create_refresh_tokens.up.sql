@@ -0,0 +1,13 @@
+CREATE TABLE refresh_tokens (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    host_id     TEXT     NOT NULL REFERENCES hosts (id) ON DELETE CASCADE,
+    family_id   TEXT     NOT NULL,
+    token_hash  TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL,
+    expires_at  DATETIME NOT NULL,
+    revoked_at  DATETIME NULL,
+    replaced_by TEXT     NULL,
+    CONSTRAINT uq_refresh_tokens_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
@@ -0,0 +1,16 @@
+CREATE TABLE song_requests (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    playlist_id TEXT     NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    title       TEXT     NOT NULL,
+    artist      TEXT     NOT NULL DEFAULT '',
+    duration    INTEGER  NOT NULL DEFAULT 0,
+    nickname    TEXT     NOT NULL,
+    client_key  TEXT     NOT NULL,
+    status      TEXT     NOT NULL,
+    track_id    TEXT     NULL,
+    created_at  DATETIME NOT NULL,
+    reviewed_at DATETIME NULL
+);
+
+CREATE INDEX idx_song_requests_status ON song_requests (playlist_id, status, created_at);
+CREATE INDEX idx_song_requests_client ON song_requests (playlist_id, client_key, created_at);
@@ -0,0 +1,8 @@
+CREATE TABLE track_votes (
+    playlist_id TEXT     NOT NULL,
+    track_id    TEXT     NOT NULL REFERENCES playlist_tracks (id) ON DELETE CASCADE,
+    voter_key   TEXT     NOT NULL,
+    value       INTEGER  NOT NULL,
+    created_at  DATETIME NOT NULL,
+    PRIMARY KEY (playlist_id, track_id, voter_key)
+);
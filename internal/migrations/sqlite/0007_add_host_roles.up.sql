@@ -0,0 +1,2 @@
+-- Existing accounts all registered as hosts
+ALTER TABLE hosts ADD COLUMN role TEXT NOT NULL DEFAULT 'host';
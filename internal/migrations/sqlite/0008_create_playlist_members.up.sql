@@ -0,0 +1,13 @@
+CREATE TABLE playlist_members (
+    playlist_id TEXT     NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    host_id     TEXT     NOT NULL REFERENCES hosts (id) ON DELETE CASCADE,
+    role        TEXT     NOT NULL,
+    added_at    DATETIME NOT NULL,
+    PRIMARY KEY (playlist_id, host_id)
+);
+
+CREATE INDEX idx_playlist_members_host ON playlist_members (host_id);
+
+-- Playlists so far belong to the host who created them
+INSERT INTO playlist_members (playlist_id, host_id, role, added_at)
+SELECT id, host_id, 'owner', created_at FROM playlists;
@@ -0,0 +1,45 @@
+-- Global rules have no playlist
+CREATE TABLE block_rules (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    playlist_id TEXT     NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    kind        TEXT     NOT NULL,
+    pattern     TEXT     NOT NULL,
+    action      TEXT     NOT NULL,
+    created_by  TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL
+);
+
+CREATE INDEX idx_block_rules_playlist ON block_rules (playlist_id, created_at);
+
+CREATE TABLE moderation_flags (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    playlist_id TEXT     NOT NULL REFERENCES playlists (id) ON DELETE CASCADE,
+    entry_id    TEXT     NOT NULL,
+    title       TEXT     NOT NULL,
+    artist      TEXT     NOT NULL DEFAULT '',
+    rule_id     TEXT     NOT NULL,
+    kind        TEXT     NOT NULL,
+    pattern     TEXT     NOT NULL,
+    status      TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL,
+    reviewed_by TEXT     NULL,
+    reviewed_at DATETIME NULL
+);
+
+CREATE INDEX idx_moderation_flags_status ON moderation_flags (status, created_at);
+CREATE INDEX idx_moderation_flags_playlist ON moderation_flags (playlist_id, created_at);
+
+-- The audit outlives the accounts, playlists and rules it mentions
+CREATE TABLE moderation_audit (
+    id          TEXT     NOT NULL PRIMARY KEY,
+    actor_id    TEXT     NULL,
+    action      TEXT     NOT NULL,
+    playlist_id TEXT     NULL,
+    target_id   TEXT     NULL,
+    detail      TEXT     NOT NULL DEFAULT '',
+    created_at  DATETIME NOT NULL
+);
+
+CREATE INDEX idx_moderation_audit_created ON moderation_audit (created_at);
+CREATE INDEX idx_moderation_audit_playlist ON moderation_audit (playlist_id, created_at);
+CREATE INDEX idx_moderation_audit_actor ON moderation_audit (actor_id, created_at);
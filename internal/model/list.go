@@ -0,0 +1,48 @@
+package model
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ListOptions selects one page of a listing.
+type ListOptions struct {
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first.
+	Cursor string
+	// Sort is a field name, prefixed with "-" for descending order. Empty
+	// means the listing's default order.
+	Sort string
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PlaylistFilter narrows a playlist listing. Zero values don't filter.
+type PlaylistFilter struct {
+	IsModerated *bool
+	// Name matches playlists whose name contains it, ignoring case.
+	Name string
+}
+
+// TrackFilter narrows a track listing. Zero values don't filter.
+type TrackFilter struct {
+	// Artist and Title match tracks containing them, ignoring case.
+	Artist string
+	Title  string
+}
+
+// FlagFilter narrows the moderation review queue. Zero values don't filter.
+type FlagFilter struct {
+	Status     FlagStatus
+	PlaylistID string
+}
+
+// AuditFilter narrows the moderation audit. Zero values don't filter.
+type AuditFilter struct {
+	PlaylistID string
+	ActorID    string
+}
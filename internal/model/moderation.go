@@ -0,0 +1,92 @@
+package model
+
+import "time"
+
+// BlockKind is what a block rule matches against.
+type BlockKind string
+
+const (
+	// BlockArtist matches tracks by the whole artist name.
+	BlockArtist BlockKind = "artist"
+	// BlockTitle matches tracks by the whole title.
+	BlockTitle BlockKind = "title"
+	// BlockWord matches tracks whose title or artist contains the word,
+	// or the words in sequence.
+	BlockWord BlockKind = "word"
+)
+
+// BlockAction is what happens to a track matching a block rule.
+type BlockAction string
+
+const (
+	// BlockReject refuses the track.
+	BlockReject BlockAction = "reject"
+	// BlockFlag lets the track in and queues it for an admin to review.
+	BlockFlag BlockAction = "flag"
+)
+
+// BlockRule screens the tracks added to moderated playlists. Rules without
+// a PlaylistID are global and apply to every moderated playlist.
+type BlockRule struct {
+	ID         string      `json:"id"`
+	PlaylistID string      `json:"playlist_id,omitempty"`
+	Kind       BlockKind   `json:"kind"`
+	Pattern    string      `json:"pattern"`
+	Action     BlockAction `json:"action"`
+	CreatedBy  string      `json:"created_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type FlagStatus string
+
+const (
+	FlagPending FlagStatus = "pending"
+	// FlagApproved keeps the track in the playlist.
+	FlagApproved FlagStatus = "approved"
+	// FlagRemoved took the track out of the playlist.
+	FlagRemoved FlagStatus = "removed"
+)
+
+// ModerationFlag is a track let into a playlist by a flagging rule, waiting
+// in the review queue. The rule's kind and pattern are copied so the flag
+// still reads after the rule is deleted.
+type ModerationFlag struct {
+	ID         string     `json:"id"`
+	PlaylistID string     `json:"playlist_id"`
+	EntryID    string     `json:"entry_id"` // the flagged Playlist_Track
+	Title      string     `json:"title"`
+	Artist     string     `json:"artist"`
+	RuleID     string     `json:"rule_id"`
+	Kind       BlockKind  `json:"kind"`
+	Pattern    string     `json:"pattern"`
+	Status     FlagStatus `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type AuditAction string
+
+const (
+	AuditRuleCreated        AuditAction = "rule_created"
+	AuditRuleDeleted        AuditAction = "rule_deleted"
+	AuditTrackRejected      AuditAction = "track_rejected"
+	AuditTrackFlagged       AuditAction = "track_flagged"
+	AuditFlagApproved       AuditAction = "flag_approved"
+	AuditFlagRemoved        AuditAction = "flag_removed"
+	AuditModerationEnabled  AuditAction = "moderation_enabled"
+	AuditModerationDisabled AuditAction = "moderation_disabled"
+)
+
+// AuditEntry records a moderation decision. ActorID is empty for decisions
+// taken on behalf of anonymous guests.
+type AuditEntry struct {
+	ID         string      `json:"id"`
+	ActorID    string      `json:"actor_id,omitempty"`
+	Action     AuditAction `json:"action"`
+	PlaylistID string      `json:"playlist_id,omitempty"`
+	// TargetID is the rule, flag or track entry the action applies to.
+	TargetID  string    `json:"target_id,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
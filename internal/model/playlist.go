@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+)
 
 type Playlist struct {
 	ID          string    `json:"id"`
@@ -9,31 +13,61 @@ type Playlist struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	IsModerated bool      `json:"is_moderated"`
+	// VoteOrdered makes upcoming tracks follow their vote score.
+	VoteOrdered bool `json:"vote_ordered"`
 }
 
+// Track is a catalog entry that any number of playlists can reference.
 type Track struct {
-	ID       string    `json:"id"`
-	Title    string    `json:"title"`
-	Artist   string    `json:"artist"`
-	Duration int       `json:"duration"` // in seconds
-	AddedAt  time.Time `json:"added_at"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Duration  int       `json:"duration"` // in seconds
+	CreatedAt time.Time `json:"created_at"`
 }
 
+// Playlist_Track is an entry of a playlist: a catalog track at a position.
+// ID identifies the entry, so the same track may appear more than once.
 type Playlist_Track struct {
-	ID         string    `json:"id"`
+	ID         string     `json:"id"`
+	PlaylistID string     `json:"playlist_id"`
+	TrackID    string     `json:"track_id"` // catalog track
+	Title      string     `json:"title"`
+	Artist     string     `json:"artist"`
+	Duration   int        `json:"duration"` // in seconds
+	Position   int        `json:"position"` // order in playlist
+	AddedAt    time.Time  `json:"added_at"`
+	IsPlaying  bool       `json:"is_playing"`
+	StartedAt  *time.Time `json:"started_at,omitempty"` // when the track started playing
+	Score      int        `json:"score"`                // sum of patron votes
+}
+
+// Vote is a patron's up (+1) or down (-1) vote on a playlist entry.
+type Vote struct {
 	PlaylistID string    `json:"playlist_id"`
-	Title      string    `json:"title"`
-	Artist     string    `json:"artist"`
-	Duration   int       `json:"duration"` // in seconds
-	Position   int       `json:"position"` // order in playlist
-	AddedAt    time.Time `json:"added_at"`
-	IsPlaying  bool      `json:"is_playing"`
+	TrackID    string    `json:"track_id"`
+	VoterKey   string    `json:"-"`
+	Value      int       `json:"value"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Host struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	IsActive  bool      `json:"is_active"`
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	Role         rbac.Role `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	IsActive     bool      `json:"is_active"`
+}
+
+// Member is an account's membership of a playlist. Name and Email are
+// read from the account.
+type Member struct {
+	PlaylistID string          `json:"playlist_id"`
+	HostID     string          `json:"host_id"`
+	Name       string          `json:"name"`
+	Email      string          `json:"email"`
+	Role       rbac.MemberRole `json:"role"`
+	AddedAt    time.Time       `json:"added_at"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type SongRequestStatus string
+
+const (
+	SongRequestPending  SongRequestStatus = "pending"
+	SongRequestApproved SongRequestStatus = "approved"
+	SongRequestRejected SongRequestStatus = "rejected"
+)
+
+// SongRequest is a track suggested by an anonymous patron, waiting for the
+// host to approve it into the playlist.
+type SongRequest struct {
+	ID         string            `json:"id"`
+	PlaylistID string            `json:"playlist_id"`
+	Title      string            `json:"title"`
+	Artist     string            `json:"artist"`
+	Duration   int               `json:"duration"` // in seconds
+	Nickname   string            `json:"nickname"`
+	ClientKey  string            `json:"-"` // hashed client address, for rate limiting
+	Status     SongRequestStatus `json:"status"`
+	TrackID    string            `json:"track_id,omitempty"` // set once approved
+	CreatedAt  time.Time         `json:"created_at"`
+	ReviewedAt *time.Time        `json:"reviewed_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// RefreshToken is the server-side record of an issued refresh token. Tokens
+// rotated from the same login share a FamilyID.
+type RefreshToken struct {
+	ID         string     `json:"id"`
+	HostID     string     `json:"host_id"`
+	FamilyID   string     `json:"family_id"`
+	TokenHash  string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+}
@@ -0,0 +1,129 @@
+// Package rbac defines account roles and what they permit. An account's
+// role grants permissions on every playlist; its membership of a particular
+// playlist, as owner, editor or viewer, grants more on that playlist only.
+package rbac
+
+// Role is the role of an account. Roles form a hierarchy where each role
+// holds the permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer can only look at public playlists.
+	RoleViewer Role = "viewer"
+	// RoleStaff is a DJ or venue employee who edits the playlists a host
+	// invited them to.
+	RoleStaff Role = "staff"
+	// RoleHost is a venue owner who creates playlists.
+	RoleHost Role = "host"
+	// RoleAdmin runs the service and may act on any playlist.
+	RoleAdmin Role = "admin"
+)
+
+// hierarchy lists the roles from least to most privileged.
+var hierarchy = []Role{RoleViewer, RoleStaff, RoleHost, RoleAdmin}
+
+// Permission is an action on playlists.
+type Permission string
+
+const (
+	PermPlaylistView   Permission = "playlist:view"
+	PermPlaylistCreate Permission = "playlist:create"
+	// PermPlaylistRead covers what only members see: the other members
+	// and the song requests waiting for review.
+	PermPlaylistRead Permission = "playlist:read"
+	// PermPlaylistEdit covers the playlist's details, its tracks and
+	// playback.
+	PermPlaylistEdit Permission = "playlist:edit"
+	// PermPlaylistModerate covers reviewing what guests submit.
+	PermPlaylistModerate Permission = "playlist:moderate"
+	// PermPlaylistManage covers deleting the playlist and choosing its
+	// members.
+	PermPlaylistManage Permission = "playlist:manage"
+	// PermModerationManage covers the global blocklist, the review queue,
+	// the audit and switching moderation on and off for a playlist.
+	PermModerationManage Permission = "moderation:manage"
+)
+
+// roleGrants are the permissions each role adds to those of the roles below
+// it. They apply to every playlist.
+var roleGrants = map[Role][]Permission{
+	RoleViewer: {PermPlaylistView},
+	RoleHost:   {PermPlaylistCreate},
+	RoleAdmin:  {PermPlaylistRead, PermPlaylistEdit, PermPlaylistModerate, PermPlaylistManage, PermModerationManage},
+}
+
+// MemberRole is an account's role in a particular playlist.
+type MemberRole string
+
+const (
+	// MemberOwner is the host who created the playlist.
+	MemberOwner MemberRole = "owner"
+	// MemberEditor helps run the playlist. Only staff accounts and above
+	// can be editors.
+	MemberEditor MemberRole = "editor"
+	// MemberViewer follows the playlist from the host's side without
+	// changing it.
+	MemberViewer MemberRole = "viewer"
+)
+
+// memberGrants are the permissions a member role grants on its playlist.
+var memberGrants = map[MemberRole][]Permission{
+	MemberOwner:  {PermPlaylistRead, PermPlaylistEdit, PermPlaylistModerate, PermPlaylistManage},
+	MemberEditor: {PermPlaylistRead, PermPlaylistEdit, PermPlaylistModerate},
+	MemberViewer: {PermPlaylistRead},
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r.rank() >= 0
+}
+
+// AtLeast reports whether r is min or a role above it.
+func (r Role) AtLeast(min Role) bool {
+	return r.rank() >= 0 && r.rank() >= min.rank()
+}
+
+// Can reports whether r grants p on every playlist.
+func (r Role) Can(p Permission) bool {
+	for i := 0; i <= r.rank(); i++ {
+		if contains(roleGrants[hierarchy[i]], p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) rank() int {
+	for i, role := range hierarchy {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid reports whether m is a known member role.
+func (m MemberRole) Valid() bool {
+	_, ok := memberGrants[m]
+	return ok
+}
+
+// Can reports whether m grants p on its playlist.
+func (m MemberRole) Can(p Permission) bool {
+	return contains(memberGrants[m], p)
+}
+
+func contains(perms []Permission, p Permission) bool {
+	for _, perm := range perms {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is the account a request acts as.
+type Principal struct {
+	UserID string
+	Role   Role
+}
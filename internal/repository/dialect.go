@@ -0,0 +1,114 @@
+package repository
+
+import (
+	stderrors "errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// dialect covers the SQL differences between databases for repositories
+// whose queries are otherwise portable. Queries are written for MySQL and
+// rewritten with rebind.
+type dialect struct {
+	// system names the database in traces.
+	system string
+	// numbered is true for drivers that use $1, $2... placeholders.
+	numbered bool
+	// upsertMySQL is true for ON DUPLICATE KEY UPDATE, false for
+	// ON CONFLICT ... DO UPDATE.
+	upsertMySQL bool
+	// rowLocks is true for databases that lock rows with FOR UPDATE.
+	rowLocks          bool
+	isUniqueViolation func(err error) bool
+}
+
+// mysqlErrDuplicateEntry is the MySQL error number for unique key violations.
+const mysqlErrDuplicateEntry = 1062
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique key violations.
+const pgUniqueViolation = "23505"
+
+var mysqlDialect = dialect{
+	system:      "mysql",
+	upsertMySQL: true,
+	rowLocks:    true,
+	isUniqueViolation: func(err error) bool {
+		var mysqlErr *mysql.MySQLError
+		return stderrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+	},
+}
+
+var postgresDialect = dialect{
+	system:   "postgresql",
+	numbered: true,
+	rowLocks: true,
+	isUniqueViolation: func(err error) bool {
+		var pgErr *pgconn.PgError
+		return stderrors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+	},
+}
+
+var sqliteDialect = dialect{
+	system: "sqlite",
+	// Transactions take the write lock when they begin (_txlock=immediate
+	// in config.NewDB), so no row locks are needed.
+	// SQLite reports duplicate primary keys with their own extended code.
+	isUniqueViolation: func(err error) bool {
+		var sqliteErr *sqlite.Error
+		if !stderrors.As(err, &sqliteErr) {
+			return false
+		}
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	},
+}
+
+// rebind rewrites ? placeholders for the dialect. Queries must not contain
+// a literal question mark.
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// forUpdate returns the suffix of a SELECT that locks the rows it reads
+// until the end of the transaction.
+func (d dialect) forUpdate() string {
+	if !d.rowLocks {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// upsert returns the clause that turns an INSERT into an update of columns
+// when a row with the same key exists.
+func (d dialect) upsert(key []string, columns ...string) string {
+	sets := make([]string, len(columns))
+	if d.upsertMySQL {
+		for i, column := range columns {
+			sets[i] = column + " = VALUES(" + column + ")"
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+	for i, column := range columns {
+		sets[i] = column + " = excluded." + column
+	}
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type HostRepository interface {
+	CreateHost(ctx context.Context, host *model.Host) error
+	GetHost(ctx context.Context, id string) (*model.Host, error)
+	GetHostByEmail(ctx context.Context, email string) (*model.Host, error)
+}
+
+type hostRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewHostRepository(db *sql.DB) HostRepository {
+	return &hostRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *hostRepository) CreateHost(ctx context.Context, host *model.Host) error {
+	query := `
+		INSERT INTO hosts (id, name, email, password_hash, role, created_at, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		host.ID,
+		host.Name,
+		host.Email,
+		host.PasswordHash,
+		host.Role,
+		host.CreatedAt,
+		host.IsActive,
+	)
+	if r.dialect.isUniqueViolation(err) {
+		return errors.ErrEmailTaken
+	}
+	return err
+}
+
+func (r *hostRepository) GetHost(ctx context.Context, id string) (*model.Host, error) {
+	query := `
+		SELECT id, name, email, password_hash, role, created_at, is_active
+		FROM hosts
+		WHERE id = ?
+	`
+	return r.scanHost(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+}
+
+func (r *hostRepository) GetHostByEmail(ctx context.Context, email string) (*model.Host, error) {
+	query := `
+		SELECT id, name, email, password_hash, role, created_at, is_active
+		FROM hosts
+		WHERE email = ?
+	`
+	return r.scanHost(r.db.QueryRowContext(ctx, r.dialect.rebind(query), email))
+}
+
+func (r *hostRepository) scanHost(row *sql.Row) (*model.Host, error) {
+	host := &model.Host{}
+	err := row.Scan(
+		&host.ID,
+		&host.Name,
+		&host.Email,
+		&host.PasswordHash,
+		&host.Role,
+		&host.CreatedAt,
+		&host.IsActive,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrHostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return host, nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type sortKind int
+
+const (
+	sortString sortKind = iota
+	sortInt
+	sortTime
+)
+
+// sortField is a field a listing of T can be sorted by.
+type sortField[T any] struct {
+	column string
+	kind   sortKind
+	// value returns the field of an item: a string, int or time.Time
+	// according to kind.
+	value func(T) any
+}
+
+var playlistSorts = map[string]sortField[*model.Playlist]{
+	"created_at": {"p.created_at", sortTime, func(p *model.Playlist) any { return p.CreatedAt }},
+	"name":       {"p.name", sortString, func(p *model.Playlist) any { return p.Name }},
+}
+
+var trackSorts = map[string]sortField[*model.Playlist_Track]{
+	"position": {"pt.position", sortInt, func(t *model.Playlist_Track) any { return t.Position }},
+	"added_at": {"pt.added_at", sortTime, func(t *model.Playlist_Track) any { return t.AddedAt }},
+	"title":    {"t.title", sortString, func(t *model.Playlist_Track) any { return t.Title }},
+}
+
+// cursor is the decoded form of an opaque page cursor: the sort it was
+// issued for and the sort value and ID of the last item returned. Items
+// with equal sort values are ordered by ID, so the position is exact.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// listing is a validated model.ListOptions for items of type T.
+type listing[T any] struct {
+	sort  string
+	field sortField[T]
+	desc  bool
+	limit int
+	id    func(T) string
+	// after is the decoded cursor, nil for the first page.
+	after      *cursor
+	afterValue any
+}
+
+func newListing[T any](fields map[string]sortField[T], defaultSort string, id func(T) string, opts model.ListOptions) (*listing[T], error) {
+	sort := opts.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	name, desc := strings.CutPrefix(sort, "-")
+	field, ok := fields[name]
+	if !ok {
+		return nil, errors.ErrInvalidSort
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = model.DefaultPageLimit
+	}
+	l := &listing[T]{sort: sort, field: field, desc: desc, limit: limit, id: id}
+
+	if opts.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(opts.Cursor)
+		if err != nil {
+			return nil, errors.ErrInvalidCursor
+		}
+		var c cursor
+		if err := json.Unmarshal(raw, &c); err != nil || c.Sort != sort || c.ID == "" {
+			return nil, errors.ErrInvalidCursor
+		}
+		value, err := parseSortValue(field.kind, c.Value)
+		if err != nil {
+			return nil, errors.ErrInvalidCursor
+		}
+		l.after = &c
+		l.afterValue = value
+	}
+	return l, nil
+}
+
+func parseSortValue(kind sortKind, value string) (any, error) {
+	switch kind {
+	case sortInt:
+		return strconv.Atoi(value)
+	case sortTime:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
+}
+
+func formatSortValue(value any) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v.(string)
+	}
+}
+
+// where returns the condition selecting items after the cursor, or "" on
+// the first page.
+func (l *listing[T]) where(idColumn string) (string, []any) {
+	if l.after == nil {
+		return "", nil
+	}
+	op := ">"
+	if l.desc {
+		op = "<"
+	}
+	cond := "(" + l.field.column + " " + op + " ? OR (" + l.field.column + " = ? AND " + idColumn + " " + op + " ?))"
+	return cond, []any{l.afterValue, l.afterValue, l.after.ID}
+}
+
+func (l *listing[T]) orderBy(idColumn string) string {
+	dir := " ASC"
+	if l.desc {
+		dir = " DESC"
+	}
+	return " ORDER BY " + l.field.column + dir + ", " + idColumn + dir + " LIMIT " + strconv.Itoa(l.limit+1)
+}
+
+// page builds the page from up to limit+1 sorted items, the extra one only
+// telling that there is a next page.
+func (l *listing[T]) page(items []T) *model.Page[T] {
+	page := &model.Page[T]{Items: make([]T, 0, len(items))}
+	if len(items) > l.limit {
+		items = items[:l.limit]
+		last := items[len(items)-1]
+		raw, _ := json.Marshal(cursor{
+			Sort:  l.sort,
+			Value: formatSortValue(l.field.value(last)),
+			ID:    l.id(last),
+		})
+		page.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	page.Items = append(page.Items, items...)
+	return page
+}
+
+// less orders items for in-memory listings the way orderBy does in SQL.
+func (l *listing[T]) less(a, b T) bool {
+	c := compareSortValues(l.field.value(a), l.field.value(b))
+	if c == 0 {
+		c = strings.Compare(l.id(a), l.id(b))
+	}
+	if l.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// isAfter reports whether item belongs after the cursor, like where.
+func (l *listing[T]) isAfter(item T) bool {
+	if l.after == nil {
+		return true
+	}
+	c := compareSortValues(l.field.value(item), l.afterValue)
+	if c == 0 {
+		c = strings.Compare(l.id(item), l.after.ID)
+	}
+	if l.desc {
+		return c < 0
+	}
+	return c > 0
+}
+
+func compareSortValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return a - b.(int)
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// containsPattern returns a LIKE pattern, used with ESCAPE '!', matching
+// values that contain s.
+func containsPattern(s string) string {
+	replacer := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return "%" + replacer.Replace(strings.ToLower(s)) + "%"
+}
+
+// listPlaylistsByHost implements ListPlaylistsByHost for SQL databases.
+func listPlaylistsByHost(ctx context.Context, db *sql.DB, d dialect, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error) {
+	l, err := newListing(playlistSorts, "created_at", func(p *model.Playlist) string { return p.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"p.host_id = ?"}
+	args := []any{hostID}
+	if filter.IsModerated != nil {
+		conditions = append(conditions, "p.is_moderated = ?")
+		args = append(args, *filter.IsModerated)
+	}
+	if filter.Name != "" {
+		conditions = append(conditions, "LOWER(p.name) LIKE ? ESCAPE '!'")
+		args = append(args, containsPattern(filter.Name))
+	}
+	if cond, condArgs := l.where("p.id"); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+
+	query := `
+		SELECT p.id, p.name, p.host_id, p.created_at, p.updated_at, p.is_moderated, p.vote_ordered
+		FROM playlists p
+		WHERE ` + strings.Join(conditions, " AND ") + l.orderBy("p.id")
+	rows, err := db.QueryContext(ctx, d.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var playlists []*model.Playlist
+	for rows.Next() {
+		playlist := &model.Playlist{}
+		err := rows.Scan(
+			&playlist.ID,
+			&playlist.Name,
+			&playlist.HostID,
+			&playlist.CreatedAt,
+			&playlist.UpdatedAt,
+			&playlist.IsModerated,
+			&playlist.VoteOrdered,
+		)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, playlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return l.page(playlists), nil
+}
+
+// listPlaylistTracks implements ListPlaylistTracks for SQL databases.
+func listPlaylistTracks(ctx context.Context, db *sql.DB, d dialect, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error) {
+	l, err := newListing(trackSorts, "position", func(t *model.Playlist_Track) string { return t.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := []string{"pt.playlist_id = ?"}
+	args := []any{playlistID}
+	if filter.Artist != "" {
+		conditions = append(conditions, "LOWER(t.artist) LIKE ? ESCAPE '!'")
+		args = append(args, containsPattern(filter.Artist))
+	}
+	if filter.Title != "" {
+		conditions = append(conditions, "LOWER(t.title) LIKE ? ESCAPE '!'")
+		args = append(args, containsPattern(filter.Title))
+	}
+	if cond, condArgs := l.where("pt.id"); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+
+	query := `
+		SELECT ` + playlistTrackColumns + `
+		FROM playlist_tracks pt
+		INNER JOIN tracks t ON t.id = pt.track_id
+		WHERE ` + strings.Join(conditions, " AND ") + l.orderBy("pt.id")
+	rows, err := db.QueryContext(ctx, d.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tracks []*model.Playlist_Track
+	for rows.Next() {
+		track, err := scanPlaylistTrack(rows)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return l.page(tracks), nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+)
+
+// MemberRepository stores who belongs to each playlist. CreatePlaylist
+// adds the playlist's host as its owner.
+type MemberRepository interface {
+	// AddPlaylistMember fails with ErrAlreadyMember if the account already
+	// belongs to the playlist.
+	AddPlaylistMember(ctx context.Context, member *model.Member) error
+	RemovePlaylistMember(ctx context.Context, playlistID, hostID string) error
+	// GetPlaylistMemberRole fails with ErrMemberNotFound if the account
+	// doesn't belong to the playlist.
+	GetPlaylistMemberRole(ctx context.Context, playlistID, hostID string) (rbac.MemberRole, error)
+	// GetPlaylistMembers lists the members in the order they joined, so the
+	// owner comes first.
+	GetPlaylistMembers(ctx context.Context, playlistID string) ([]*model.Member, error)
+}
+
+type memberRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewMemberRepository(db *sql.DB) MemberRepository {
+	return &memberRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *memberRepository) AddPlaylistMember(ctx context.Context, member *model.Member) error {
+	query := `
+		INSERT INTO playlist_members (playlist_id, host_id, role, added_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		member.PlaylistID,
+		member.HostID,
+		member.Role,
+		member.AddedAt,
+	)
+	if r.dialect.isUniqueViolation(err) {
+		return errors.ErrAlreadyMember
+	}
+	return err
+}
+
+func (r *memberRepository) RemovePlaylistMember(ctx context.Context, playlistID, hostID string) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(
+		"DELETE FROM playlist_members WHERE playlist_id = ? AND host_id = ?"),
+		playlistID, hostID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *memberRepository) GetPlaylistMemberRole(ctx context.Context, playlistID, hostID string) (rbac.MemberRole, error) {
+	var role rbac.MemberRole
+	err := r.db.QueryRowContext(ctx, r.dialect.rebind(
+		"SELECT role FROM playlist_members WHERE playlist_id = ? AND host_id = ?"),
+		playlistID, hostID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", errors.ErrMemberNotFound
+	}
+	return role, err
+}
+
+func (r *memberRepository) GetPlaylistMembers(ctx context.Context, playlistID string) ([]*model.Member, error) {
+	query := `
+		SELECT pm.playlist_id, h.id, h.name, h.email, pm.role, pm.added_at
+		FROM playlist_members pm
+		INNER JOIN hosts h ON h.id = pm.host_id
+		WHERE pm.playlist_id = ?
+		ORDER BY pm.added_at, h.id
+	`
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), playlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*model.Member
+	for rows.Next() {
+		member := &model.Member{}
+		err := rows.Scan(
+			&member.PlaylistID,
+			&member.HostID,
+			&member.Name,
+			&member.Email,
+			&member.Role,
+			&member.AddedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
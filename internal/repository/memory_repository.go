@@ -0,0 +1,945 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+)
+
+// memoryRepository keeps everything in process memory. It implements every
+// repository interface with the same semantics as the MySQL implementation,
+// which makes it suitable for tests and for running the server without a
+// database. Values are copied in and out so callers can't mutate the store.
+type memoryRepository struct {
+	mu        sync.RWMutex
+	playlists map[string]*model.Playlist
+	tracks    map[string]*model.Track
+	// entries holds each playlist's entries by entry ID.
+	entries  map[string]map[string]*model.Playlist_Track
+	hosts    map[string]*model.Host
+	tokens   map[string]*model.RefreshToken
+	requests map[string]*model.SongRequest
+	votes    map[voteKey]*model.Vote
+	// members holds each playlist's members by host ID. Name and Email
+	// are filled in from hosts when listing.
+	members map[string]map[string]model.Member
+	rules   map[string]*model.BlockRule
+	flags   map[string]*model.ModerationFlag
+	audit   []*model.AuditEntry
+}
+
+type voteKey struct {
+	playlistID string
+	trackID    string
+	voterKey   string
+}
+
+// NewMemoryRepository returns a Repository backed by process memory. Data
+// is lost when the process exits.
+func NewMemoryRepository() Repository {
+	store := newMemoryRepository()
+	return &repository{
+		PlaylistRepository:   store,
+		hostRepository:       store,
+		tokenRepository:      store,
+		requestRepository:    store,
+		voteRepository:       store,
+		memberRepository:     store,
+		moderationRepository: store,
+	}
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		playlists: make(map[string]*model.Playlist),
+		tracks:    make(map[string]*model.Track),
+		entries:   make(map[string]map[string]*model.Playlist_Track),
+		hosts:     make(map[string]*model.Host),
+		tokens:    make(map[string]*model.RefreshToken),
+		requests:  make(map[string]*model.SongRequest),
+		votes:     make(map[voteKey]*model.Vote),
+		members:   make(map[string]map[string]model.Member),
+		rules:     make(map[string]*model.BlockRule),
+		flags:     make(map[string]*model.ModerationFlag),
+	}
+}
+
+// Playlists
+
+func (r *memoryRepository) CreatePlaylist(ctx context.Context, playlist *model.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[playlist.ID]; ok {
+		return fmt.Errorf("playlist %s already exists", playlist.ID)
+	}
+	stored := *playlist
+	stored.CreatedAt = time.Now()
+	stored.UpdatedAt = stored.CreatedAt
+	r.playlists[playlist.ID] = &stored
+	r.entries[playlist.ID] = make(map[string]*model.Playlist_Track)
+	r.members[playlist.ID] = map[string]model.Member{
+		playlist.HostID: {
+			PlaylistID: playlist.ID,
+			HostID:     playlist.HostID,
+			Role:       rbac.MemberOwner,
+			AddedAt:    stored.CreatedAt,
+		},
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetPlaylist(ctx context.Context, id string) (*model.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	playlist, ok := r.playlists[id]
+	if !ok {
+		return nil, errors.ErrPlaylistNotFound
+	}
+	result := *playlist
+	return &result, nil
+}
+
+func (r *memoryRepository) UpdatePlaylist(ctx context.Context, playlist *model.Playlist) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.playlists[playlist.ID]
+	if !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	stored.Name = playlist.Name
+	stored.UpdatedAt = time.Now()
+	stored.IsModerated = playlist.IsModerated
+	stored.VoteOrdered = playlist.VoteOrdered
+	return nil
+}
+
+func (r *memoryRepository) DeletePlaylist(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[id]; !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	delete(r.playlists, id)
+	delete(r.entries, id)
+	delete(r.members, id)
+	for key := range r.votes {
+		if key.playlistID == id {
+			delete(r.votes, key)
+		}
+	}
+	for requestID, request := range r.requests {
+		if request.PlaylistID == id {
+			delete(r.requests, requestID)
+		}
+	}
+	for ruleID, rule := range r.rules {
+		if rule.PlaylistID == id {
+			delete(r.rules, ruleID)
+		}
+	}
+	for flagID, flag := range r.flags {
+		if flag.PlaylistID == id {
+			delete(r.flags, flagID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetPlaylistsByHost(ctx context.Context, hostID string) ([]*model.Playlist, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var playlists []*model.Playlist
+	for _, playlist := range r.playlists {
+		if playlist.HostID == hostID {
+			result := *playlist
+			playlists = append(playlists, &result)
+		}
+	}
+	sort.Slice(playlists, func(i, j int) bool {
+		if !playlists[i].CreatedAt.Equal(playlists[j].CreatedAt) {
+			return playlists[i].CreatedAt.Before(playlists[j].CreatedAt)
+		}
+		return playlists[i].ID < playlists[j].ID
+	})
+	return playlists, nil
+}
+
+func (r *memoryRepository) ListPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error) {
+	l, err := newListing(playlistSorts, "created_at", func(p *model.Playlist) string { return p.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var playlists []*model.Playlist
+	for _, playlist := range r.playlists {
+		if playlist.HostID != hostID || !l.isAfter(playlist) {
+			continue
+		}
+		if filter.IsModerated != nil && playlist.IsModerated != *filter.IsModerated {
+			continue
+		}
+		if filter.Name != "" && !containsFold(playlist.Name, filter.Name) {
+			continue
+		}
+		result := *playlist
+		playlists = append(playlists, &result)
+	}
+	sort.Slice(playlists, func(i, j int) bool { return l.less(playlists[i], playlists[j]) })
+	if len(playlists) > l.limit+1 {
+		playlists = playlists[:l.limit+1]
+	}
+	return l.page(playlists), nil
+}
+
+// Tracks
+
+func (r *memoryRepository) GetTrack(ctx context.Context, id string) (*model.Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	track, ok := r.tracks[id]
+	if !ok {
+		return nil, errors.ErrTrackNotFound
+	}
+	result := *track
+	return &result, nil
+}
+
+func (r *memoryRepository) AddTrack(ctx context.Context, track *model.Playlist_Track) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, ok := r.entries[track.PlaylistID]
+	if !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	if _, ok := entries[track.ID]; ok {
+		return fmt.Errorf("playlist track %s already exists", track.ID)
+	}
+
+	if _, ok := r.tracks[track.TrackID]; !ok {
+		r.tracks[track.TrackID] = &model.Track{
+			ID:        track.TrackID,
+			Title:     track.Title,
+			Artist:    track.Artist,
+			Duration:  track.Duration,
+			CreatedAt: track.AddedAt,
+		}
+	}
+
+	stored := *track
+	stored.StartedAt = nil
+	stored.Score = 0
+	entries[track.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) RemoveTrack(ctx context.Context, playlistID, trackID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[playlistID][trackID]
+	if !ok {
+		return errors.ErrTrackNotFound
+	}
+	delete(r.entries[playlistID], trackID)
+	for _, other := range r.entries[playlistID] {
+		if other.Position > entry.Position {
+			other.Position--
+		}
+	}
+	for key := range r.votes {
+		if key.playlistID == playlistID && key.trackID == trackID {
+			delete(r.votes, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) UpdateTrackPosition(ctx context.Context, playlistID, trackID string, newPosition int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[playlistID][trackID]
+	if !ok {
+		return errors.ErrTrackNotFound
+	}
+
+	currentPos := entry.Position
+	for _, other := range r.entries[playlistID] {
+		if other == entry {
+			continue
+		}
+		if currentPos < newPosition && other.Position > currentPos && other.Position <= newPosition {
+			other.Position--
+		} else if currentPos > newPosition && other.Position >= newPosition && other.Position < currentPos {
+			other.Position++
+		}
+	}
+	entry.Position = newPosition
+	return nil
+}
+
+func (r *memoryRepository) ReorderTracks(ctx context.Context, playlistID string, trackIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, ok := r.entries[playlistID]
+	if !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	positions := make(map[string]int, len(entries))
+	for id, entry := range entries {
+		positions[id] = entry.Position
+	}
+	ordered, slots := reorderSlots(positions, trackIDs)
+	for i, trackID := range ordered {
+		entries[trackID].Position = slots[i]
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetCurrentTrack(ctx context.Context, playlistID string) (*model.Playlist_Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, entry := range r.sortedEntries(playlistID) {
+		if entry.IsPlaying {
+			return entry, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.Playlist_Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedEntries(playlistID), nil
+}
+
+func (r *memoryRepository) ListPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error) {
+	l, err := newListing(trackSorts, "position", func(t *model.Playlist_Track) string { return t.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tracks []*model.Playlist_Track
+	for _, track := range r.sortedEntries(playlistID) {
+		if !l.isAfter(track) {
+			continue
+		}
+		if filter.Artist != "" && !containsFold(track.Artist, filter.Artist) {
+			continue
+		}
+		if filter.Title != "" && !containsFold(track.Title, filter.Title) {
+			continue
+		}
+		tracks = append(tracks, track)
+	}
+	sort.Slice(tracks, func(i, j int) bool { return l.less(tracks[i], tracks[j]) })
+	if len(tracks) > l.limit+1 {
+		tracks = tracks[:l.limit+1]
+	}
+	return l.page(tracks), nil
+}
+
+func (r *memoryRepository) SetCurrentTrack(ctx context.Context, playlistID, trackID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, ok := r.entries[playlistID]
+	if !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	target, ok := entries[trackID]
+	if !ok {
+		return errors.ErrTrackNotFound
+	}
+	for _, entry := range entries {
+		entry.IsPlaying = false
+		entry.StartedAt = nil
+	}
+	now := time.Now()
+	target.IsPlaying = true
+	target.StartedAt = &now
+	return nil
+}
+
+func (r *memoryRepository) ClearCurrentTrack(ctx context.Context, playlistID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, ok := r.entries[playlistID]
+	if !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	for _, entry := range entries {
+		entry.IsPlaying = false
+		entry.StartedAt = nil
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetAdjacentTrack(ctx context.Context, playlistID string, position int, forward bool) (*model.Playlist_Track, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := r.sortedEntries(playlistID)
+	if forward {
+		for _, entry := range entries {
+			if entry.Position > position {
+				return entry, nil
+			}
+		}
+		return nil, nil
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Position < position {
+			return entries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// sortedEntries returns copies of a playlist's entries ordered by position,
+// filled in from the catalog and with their vote score. Callers must hold
+// r.mu.
+func (r *memoryRepository) sortedEntries(playlistID string) []*model.Playlist_Track {
+	var tracks []*model.Playlist_Track
+	for _, entry := range r.entries[playlistID] {
+		track := *entry
+		if entry.StartedAt != nil {
+			startedAt := *entry.StartedAt
+			track.StartedAt = &startedAt
+		}
+		if catalog, ok := r.tracks[entry.TrackID]; ok {
+			track.Title = catalog.Title
+			track.Artist = catalog.Artist
+			track.Duration = catalog.Duration
+		}
+		track.Score = r.score(playlistID, entry.ID)
+		tracks = append(tracks, &track)
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].Position != tracks[j].Position {
+			return tracks[i].Position < tracks[j].Position
+		}
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// score sums the votes on an entry. Callers must hold r.mu.
+func (r *memoryRepository) score(playlistID, trackID string) int {
+	score := 0
+	for key, vote := range r.votes {
+		if key.playlistID == playlistID && key.trackID == trackID {
+			score += vote.Value
+		}
+	}
+	return score
+}
+
+// Hosts
+
+func (r *memoryRepository) CreateHost(ctx context.Context, host *model.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.hosts {
+		if existing.Email == host.Email {
+			return errors.ErrEmailTaken
+		}
+	}
+	if _, ok := r.hosts[host.ID]; ok {
+		return fmt.Errorf("host %s already exists", host.ID)
+	}
+	stored := *host
+	r.hosts[host.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetHost(ctx context.Context, id string) (*model.Host, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	host, ok := r.hosts[id]
+	if !ok {
+		return nil, errors.ErrHostNotFound
+	}
+	result := *host
+	return &result, nil
+}
+
+func (r *memoryRepository) GetHostByEmail(ctx context.Context, email string) (*model.Host, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, host := range r.hosts {
+		if host.Email == email {
+			result := *host
+			return &result, nil
+		}
+	}
+	return nil, errors.ErrHostNotFound
+}
+
+// Refresh tokens
+
+func (r *memoryRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insertRefreshToken(token)
+}
+
+func (r *memoryRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			result := *token
+			return &result, nil
+		}
+	}
+	return nil, errors.ErrInvalidRefreshToken
+}
+
+func (r *memoryRepository) RotateRefreshToken(ctx context.Context, oldID string, next *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return errors.ErrRefreshTokenReused
+	}
+	if err := r.insertRefreshToken(next); err != nil {
+		return err
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedBy = next.ID
+	return nil
+}
+
+func (r *memoryRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// insertRefreshToken stores a new token. Callers must hold r.mu.
+func (r *memoryRepository) insertRefreshToken(token *model.RefreshToken) error {
+	if _, ok := r.tokens[token.ID]; ok {
+		return fmt.Errorf("refresh token %s already exists", token.ID)
+	}
+	stored := *token
+	stored.RevokedAt = nil
+	stored.ReplacedBy = ""
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+// Song requests
+
+func (r *memoryRepository) CreateSongRequest(ctx context.Context, request *model.SongRequest, limit int, window time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[request.PlaylistID]; !ok {
+		return errors.ErrPlaylistNotFound
+	}
+	if _, ok := r.requests[request.ID]; ok {
+		return fmt.Errorf("song request %s already exists", request.ID)
+	}
+	since := request.CreatedAt.Add(-window)
+	var counted []time.Time
+	for _, other := range r.requests {
+		if other.PlaylistID == request.PlaylistID && other.ClientKey == request.ClientKey && !other.CreatedAt.Before(since) {
+			counted = append(counted, other.CreatedAt)
+		}
+	}
+	if len(counted) >= limit {
+		sort.Slice(counted, func(i, j int) bool { return counted[i].Before(counted[j]) })
+		return songRequestLimitError(request, counted[len(counted)-limit], window)
+	}
+	stored := *request
+	r.requests[request.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetSongRequest(ctx context.Context, playlistID, id string) (*model.SongRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	request, ok := r.requests[id]
+	if !ok || request.PlaylistID != playlistID {
+		return nil, errors.ErrSongRequestNotFound
+	}
+	return copySongRequest(request), nil
+}
+
+func (r *memoryRepository) GetSongRequests(ctx context.Context, playlistID string, status model.SongRequestStatus) ([]*model.SongRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var requests []*model.SongRequest
+	for _, request := range r.requests {
+		if request.PlaylistID != playlistID || (status != "" && request.Status != status) {
+			continue
+		}
+		requests = append(requests, copySongRequest(request))
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if !requests[i].CreatedAt.Equal(requests[j].CreatedAt) {
+			return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+		}
+		return requests[i].ID < requests[j].ID
+	})
+	return requests, nil
+}
+
+func (r *memoryRepository) ReviewSongRequest(ctx context.Context, request *model.SongRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.requests[request.ID]
+	if !ok || stored.Status != model.SongRequestPending {
+		return errors.ErrSongRequestReviewed
+	}
+	stored.Status = request.Status
+	stored.TrackID = request.TrackID
+	if request.ReviewedAt != nil {
+		reviewedAt := *request.ReviewedAt
+		stored.ReviewedAt = &reviewedAt
+	}
+	return nil
+}
+
+func (r *memoryRepository) ReopenSongRequest(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.requests[id]; ok {
+		stored.Status = model.SongRequestPending
+		stored.TrackID = ""
+		stored.ReviewedAt = nil
+	}
+	return nil
+}
+
+func copySongRequest(request *model.SongRequest) *model.SongRequest {
+	result := *request
+	if request.ReviewedAt != nil {
+		reviewedAt := *request.ReviewedAt
+		result.ReviewedAt = &reviewedAt
+	}
+	return &result
+}
+
+// Votes
+
+func (r *memoryRepository) CastVote(ctx context.Context, vote *model.Vote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[vote.PlaylistID][vote.TrackID]; !ok {
+		return errors.ErrTrackNotFound
+	}
+	stored := *vote
+	r.votes[voteKey{vote.PlaylistID, vote.TrackID, vote.VoterKey}] = &stored
+	return nil
+}
+
+func (r *memoryRepository) RemoveVote(ctx context.Context, playlistID, trackID, voterKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.votes, voteKey{playlistID, trackID, voterKey})
+	return nil
+}
+
+func (r *memoryRepository) GetTrackScore(ctx context.Context, playlistID, trackID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.score(playlistID, trackID), nil
+}
+
+// Members
+
+func (r *memoryRepository) AddPlaylistMember(ctx context.Context, member *model.Member) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[member.PlaylistID]; !ok {
+		return fmt.Errorf("playlist %s does not exist", member.PlaylistID)
+	}
+	if _, ok := r.hosts[member.HostID]; !ok {
+		return fmt.Errorf("host %s does not exist", member.HostID)
+	}
+	if _, ok := r.members[member.PlaylistID][member.HostID]; ok {
+		return errors.ErrAlreadyMember
+	}
+	r.members[member.PlaylistID][member.HostID] = model.Member{
+		PlaylistID: member.PlaylistID,
+		HostID:     member.HostID,
+		Role:       member.Role,
+		AddedAt:    member.AddedAt,
+	}
+	return nil
+}
+
+func (r *memoryRepository) RemovePlaylistMember(ctx context.Context, playlistID, hostID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[playlistID][hostID]; !ok {
+		return errors.ErrMemberNotFound
+	}
+	delete(r.members[playlistID], hostID)
+	return nil
+}
+
+func (r *memoryRepository) GetPlaylistMemberRole(ctx context.Context, playlistID, hostID string) (rbac.MemberRole, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[playlistID][hostID]
+	if !ok {
+		return "", errors.ErrMemberNotFound
+	}
+	return member.Role, nil
+}
+
+func (r *memoryRepository) GetPlaylistMembers(ctx context.Context, playlistID string) ([]*model.Member, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []*model.Member
+	for hostID, member := range r.members[playlistID] {
+		if host, ok := r.hosts[hostID]; ok {
+			member.Name = host.Name
+			member.Email = host.Email
+		}
+		members = append(members, &member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].AddedAt.Equal(members[j].AddedAt) {
+			return members[i].AddedAt.Before(members[j].AddedAt)
+		}
+		return members[i].HostID < members[j].HostID
+	})
+	return members, nil
+}
+
+// Moderation
+
+func (r *memoryRepository) CreateBlockRule(ctx context.Context, rule *model.BlockRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[rule.ID]; ok {
+		return fmt.Errorf("block rule %s already exists", rule.ID)
+	}
+	if _, ok := r.playlists[rule.PlaylistID]; rule.PlaylistID != "" && !ok {
+		return fmt.Errorf("playlist %s does not exist", rule.PlaylistID)
+	}
+	stored := *rule
+	r.rules[rule.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetBlockRule(ctx context.Context, id string) (*model.BlockRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, errors.ErrBlockRuleNotFound
+	}
+	result := *rule
+	return &result, nil
+}
+
+func (r *memoryRepository) DeleteBlockRule(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rules[id]; !ok {
+		return errors.ErrBlockRuleNotFound
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+func (r *memoryRepository) GetBlockRules(ctx context.Context, playlistID string) ([]*model.BlockRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rules []*model.BlockRule
+	for _, rule := range r.rules {
+		if rule.PlaylistID == playlistID {
+			result := *rule
+			rules = append(rules, &result)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if !rules[i].CreatedAt.Equal(rules[j].CreatedAt) {
+			return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+func (r *memoryRepository) CreateModerationFlag(ctx context.Context, flag *model.ModerationFlag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flags[flag.ID]; ok {
+		return fmt.Errorf("moderation flag %s already exists", flag.ID)
+	}
+	if _, ok := r.playlists[flag.PlaylistID]; !ok {
+		return fmt.Errorf("playlist %s does not exist", flag.PlaylistID)
+	}
+	stored := *flag
+	r.flags[flag.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetModerationFlag(ctx context.Context, id string) (*model.ModerationFlag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flag, ok := r.flags[id]
+	if !ok {
+		return nil, errors.ErrModerationFlagNotFound
+	}
+	return copyModerationFlag(flag), nil
+}
+
+func (r *memoryRepository) ReviewModerationFlag(ctx context.Context, flag *model.ModerationFlag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.flags[flag.ID]
+	if !ok || stored.Status != model.FlagPending {
+		return errors.ErrModerationFlagReviewed
+	}
+	stored.Status = flag.Status
+	stored.ReviewedBy = flag.ReviewedBy
+	if flag.ReviewedAt != nil {
+		reviewedAt := *flag.ReviewedAt
+		stored.ReviewedAt = &reviewedAt
+	}
+	return nil
+}
+
+func (r *memoryRepository) ListModerationFlags(ctx context.Context, filter model.FlagFilter, opts model.ListOptions) (*model.Page[*model.ModerationFlag], error) {
+	l, err := newListing(flagSorts, "created_at", func(f *model.ModerationFlag) string { return f.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var flags []*model.ModerationFlag
+	for _, flag := range r.flags {
+		if !l.isAfter(flag) {
+			continue
+		}
+		if filter.Status != "" && flag.Status != filter.Status {
+			continue
+		}
+		if filter.PlaylistID != "" && flag.PlaylistID != filter.PlaylistID {
+			continue
+		}
+		flags = append(flags, copyModerationFlag(flag))
+	}
+	sort.Slice(flags, func(i, j int) bool { return l.less(flags[i], flags[j]) })
+	if len(flags) > l.limit+1 {
+		flags = flags[:l.limit+1]
+	}
+	return l.page(flags), nil
+}
+
+func copyModerationFlag(flag *model.ModerationFlag) *model.ModerationFlag {
+	result := *flag
+	if flag.ReviewedAt != nil {
+		reviewedAt := *flag.ReviewedAt
+		result.ReviewedAt = &reviewedAt
+	}
+	return &result
+}
+
+func (r *memoryRepository) AddAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *entry
+	r.audit = append(r.audit, &stored)
+	return nil
+}
+
+func (r *memoryRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter, opts model.ListOptions) (*model.Page[*model.AuditEntry], error) {
+	l, err := newListing(auditSorts, "-created_at", func(e *model.AuditEntry) string { return e.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*model.AuditEntry
+	for _, entry := range r.audit {
+		if !l.isAfter(entry) {
+			continue
+		}
+		if filter.PlaylistID != "" && entry.PlaylistID != filter.PlaylistID {
+			continue
+		}
+		if filter.ActorID != "" && entry.ActorID != filter.ActorID {
+			continue
+		}
+		result := *entry
+		entries = append(entries, &result)
+	}
+	sort.Slice(entries, func(i, j int) bool { return l.less(entries[i], entries[j]) })
+	if len(entries) > l.limit+1 {
+		entries = entries[:l.limit+1]
+	}
+	return l.page(entries), nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type ModerationRepository interface {
+	CreateBlockRule(ctx context.Context, rule *model.BlockRule) error
+	GetBlockRule(ctx context.Context, id string) (*model.BlockRule, error)
+	DeleteBlockRule(ctx context.Context, id string) error
+	// GetBlockRules lists a playlist's rules oldest first, or the global
+	// rules if playlistID is empty.
+	GetBlockRules(ctx context.Context, playlistID string) ([]*model.BlockRule, error)
+
+	CreateModerationFlag(ctx context.Context, flag *model.ModerationFlag) error
+	GetModerationFlag(ctx context.Context, id string) (*model.ModerationFlag, error)
+	// ReviewModerationFlag moves a pending flag to its status. It fails
+	// with ErrModerationFlagReviewed if the flag is no longer pending.
+	ReviewModerationFlag(ctx context.Context, flag *model.ModerationFlag) error
+	// ListModerationFlags returns one page of flags, oldest first by
+	// default.
+	ListModerationFlags(ctx context.Context, filter model.FlagFilter, opts model.ListOptions) (*model.Page[*model.ModerationFlag], error)
+
+	AddAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+	// ListAuditEntries returns one page of the audit, newest first by
+	// default.
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter, opts model.ListOptions) (*model.Page[*model.AuditEntry], error)
+}
+
+var flagSorts = map[string]sortField[*model.ModerationFlag]{
+	"created_at": {"created_at", sortTime, func(f *model.ModerationFlag) any { return f.CreatedAt }},
+}
+
+var auditSorts = map[string]sortField[*model.AuditEntry]{
+	"created_at": {"created_at", sortTime, func(e *model.AuditEntry) any { return e.CreatedAt }},
+}
+
+type moderationRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewModerationRepository(db *sql.DB) ModerationRepository {
+	return &moderationRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *moderationRepository) CreateBlockRule(ctx context.Context, rule *model.BlockRule) error {
+	query := `
+		INSERT INTO block_rules (id, playlist_id, kind, pattern, action, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		rule.ID,
+		nullString(rule.PlaylistID),
+		rule.Kind,
+		rule.Pattern,
+		rule.Action,
+		rule.CreatedBy,
+		rule.CreatedAt,
+	)
+	return err
+}
+
+func (r *moderationRepository) GetBlockRule(ctx context.Context, id string) (*model.BlockRule, error) {
+	query := `
+		SELECT id, playlist_id, kind, pattern, action, created_by, created_at
+		FROM block_rules
+		WHERE id = ?
+	`
+	rule, err := scanBlockRule(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrBlockRuleNotFound
+	}
+	return rule, err
+}
+
+func (r *moderationRepository) DeleteBlockRule(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind("DELETE FROM block_rules WHERE id = ?"), id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrBlockRuleNotFound
+	}
+	return nil
+}
+
+func (r *moderationRepository) GetBlockRules(ctx context.Context, playlistID string) ([]*model.BlockRule, error) {
+	query := `
+		SELECT id, playlist_id, kind, pattern, action, created_by, created_at
+		FROM block_rules
+	`
+	var args []any
+	if playlistID == "" {
+		query += " WHERE playlist_id IS NULL"
+	} else {
+		query += " WHERE playlist_id = ?"
+		args = append(args, playlistID)
+	}
+	query += " ORDER BY created_at, id"
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*model.BlockRule
+	for rows.Next() {
+		rule, err := scanBlockRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *moderationRepository) CreateModerationFlag(ctx context.Context, flag *model.ModerationFlag) error {
+	query := `
+		INSERT INTO moderation_flags (id, playlist_id, entry_id, title, artist, rule_id, kind, pattern, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		flag.ID,
+		flag.PlaylistID,
+		flag.EntryID,
+		flag.Title,
+		flag.Artist,
+		flag.RuleID,
+		flag.Kind,
+		flag.Pattern,
+		flag.Status,
+		flag.CreatedAt,
+	)
+	return err
+}
+
+const moderationFlagColumns = `id, playlist_id, entry_id, title, artist, rule_id, kind, pattern, status, created_at, reviewed_by, reviewed_at`
+
+func (r *moderationRepository) GetModerationFlag(ctx context.Context, id string) (*model.ModerationFlag, error) {
+	query := `SELECT ` + moderationFlagColumns + ` FROM moderation_flags WHERE id = ?`
+	flag, err := scanModerationFlag(r.db.QueryRowContext(ctx, r.dialect.rebind(query), id))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrModerationFlagNotFound
+	}
+	return flag, err
+}
+
+func (r *moderationRepository) ReviewModerationFlag(ctx context.Context, flag *model.ModerationFlag) error {
+	query := `
+		UPDATE moderation_flags
+		SET status = ?, reviewed_by = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		flag.Status,
+		nullString(flag.ReviewedBy),
+		flag.ReviewedAt,
+		flag.ID,
+		model.FlagPending,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrModerationFlagReviewed
+	}
+	return nil
+}
+
+func (r *moderationRepository) ListModerationFlags(ctx context.Context, filter model.FlagFilter, opts model.ListOptions) (*model.Page[*model.ModerationFlag], error) {
+	l, err := newListing(flagSorts, "created_at", func(f *model.ModerationFlag) string { return f.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []any
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.PlaylistID != "" {
+		conditions = append(conditions, "playlist_id = ?")
+		args = append(args, filter.PlaylistID)
+	}
+	if cond, condArgs := l.where("id"); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+
+	query := `SELECT ` + moderationFlagColumns + ` FROM moderation_flags` + whereClause(conditions) + l.orderBy("id")
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []*model.ModerationFlag
+	for rows.Next() {
+		flag, err := scanModerationFlag(rows)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return l.page(flags), nil
+}
+
+func (r *moderationRepository) AddAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	query := `
+		INSERT INTO moderation_audit (id, actor_id, action, playlist_id, target_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		entry.ID,
+		nullString(entry.ActorID),
+		entry.Action,
+		nullString(entry.PlaylistID),
+		nullString(entry.TargetID),
+		entry.Detail,
+		entry.CreatedAt,
+	)
+	return err
+}
+
+func (r *moderationRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter, opts model.ListOptions) (*model.Page[*model.AuditEntry], error) {
+	l, err := newListing(auditSorts, "-created_at", func(e *model.AuditEntry) string { return e.ID }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []any
+	if filter.PlaylistID != "" {
+		conditions = append(conditions, "playlist_id = ?")
+		args = append(args, filter.PlaylistID)
+	}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if cond, condArgs := l.where("id"); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+
+	query := `
+		SELECT id, actor_id, action, playlist_id, target_id, detail, created_at
+		FROM moderation_audit` + whereClause(conditions) + l.orderBy("id")
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry := &model.AuditEntry{}
+		var actorID, playlistID, targetID sql.NullString
+		err := rows.Scan(
+			&entry.ID,
+			&actorID,
+			&entry.Action,
+			&playlistID,
+			&targetID,
+			&entry.Detail,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.ActorID = actorID.String
+		entry.PlaylistID = playlistID.String
+		entry.TargetID = targetID.String
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return l.page(entries), nil
+}
+
+// whereClause joins conditions into a WHERE clause, or "" if there are none.
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func scanBlockRule(row rowScanner) (*model.BlockRule, error) {
+	rule := &model.BlockRule{}
+	var playlistID sql.NullString
+	err := row.Scan(
+		&rule.ID,
+		&playlistID,
+		&rule.Kind,
+		&rule.Pattern,
+		&rule.Action,
+		&rule.CreatedBy,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rule.PlaylistID = playlistID.String
+	return rule, nil
+}
+
+func scanModerationFlag(row rowScanner) (*model.ModerationFlag, error) {
+	flag := &model.ModerationFlag{}
+	var reviewedBy sql.NullString
+	var reviewedAt sql.NullTime
+	err := row.Scan(
+		&flag.ID,
+		&flag.PlaylistID,
+		&flag.EntryID,
+		&flag.Title,
+		&flag.Artist,
+		&flag.RuleID,
+		&flag.Kind,
+		&flag.Pattern,
+		&flag.Status,
+		&flag.CreatedAt,
+		&reviewedBy,
+		&reviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	flag.ReviewedBy = reviewedBy.String
+	if reviewedAt.Valid {
+		flag.ReviewedAt = &reviewedAt.Time
+	}
+	return flag, nil
+}
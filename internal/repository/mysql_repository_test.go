@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/dmarquinah/publist_backend/internal/migrations"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/dmarquinah/publist_backend/internal/repository/repotest"
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// TestMySQLRepository runs the suite against the database in TEST_MYSQL_DSN,
+// for example
+//
+//	docker run -d -p 3307:3306 -e MYSQL_ROOT_PASSWORD=test -e MYSQL_DATABASE=publist_test mysql:8
+//	TEST_MYSQL_DSN='root:test@tcp(localhost:3307)/publist_test?parseTime=true' go test ./internal/repository/...
+//
+// Migrations are applied first. Tests use fresh IDs, so the database doesn't
+// need to be empty, but it shouldn't be one you care about.
+func TestMySQLRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN not set")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, "mysql")
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+
+	repo := repository.NewRepository(db)
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repo
+	})
+}
@@ -3,63 +3,116 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"sort"
 	"time"
 
 	"github.com/dmarquinah/publist_backend/internal/errors"
 	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
 )
 
 type PlaylistRepository interface {
+	// CreatePlaylist also makes the playlist's host its owner member.
 	CreatePlaylist(ctx context.Context, playlist *model.Playlist) error
 	GetPlaylist(ctx context.Context, id string) (*model.Playlist, error)
 	UpdatePlaylist(ctx context.Context, playlist *model.Playlist) error
 	DeletePlaylist(ctx context.Context, id string) error
 	GetPlaylistsByHost(ctx context.Context, hostID string) ([]*model.Playlist, error)
+	// ListPlaylistsByHost returns one page of a host's playlists, sorted by
+	// created_at by default. It fails with ErrInvalidSort or
+	// ErrInvalidCursor for options it can't use.
+	ListPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error)
+	// GetTrack returns a catalog track.
+	GetTrack(ctx context.Context, id string) (*model.Track, error)
+	// AddTrack adds an entry to a playlist, creating its catalog track
+	// if TrackID doesn't exist yet.
 	AddTrack(ctx context.Context, track *model.Playlist_Track) error
+	// RemoveTrack removes an entry and closes the gap in positions.
 	RemoveTrack(ctx context.Context, playlistID, trackID string) error
 	UpdateTrackPosition(ctx context.Context, playlistID, trackID string, newPosition int) error
+	// ReorderTracks puts the given entries in the given order, using the
+	// positions they already hold between them, in one transaction.
+	// Entries that are no longer in the playlist are skipped.
+	ReorderTracks(ctx context.Context, playlistID string, trackIDs []string) error
 	GetCurrentTrack(ctx context.Context, playlistID string) (*model.Playlist_Track, error)
 	GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.Playlist_Track, error)
+	// ListPlaylistTracks returns one page of a playlist's tracks, sorted by
+	// position by default.
+	ListPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error)
+	// SetCurrentTrack marks trackID as the only playing track of the playlist.
+	SetCurrentTrack(ctx context.Context, playlistID, trackID string) error
+	// ClearCurrentTrack marks none of the playlist's tracks as playing.
+	ClearCurrentTrack(ctx context.Context, playlistID string) error
+	// GetAdjacentTrack returns the closest track after (or before, when
+	// forward is false) the given position, or nil if there is none.
+	GetAdjacentTrack(ctx context.Context, playlistID string, position int, forward bool) (*model.Playlist_Track, error)
 }
 
 type playlistRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect dialect
 }
 
+// NewPlaylistRepository returns the MySQL-backed PlaylistRepository.
 func NewPlaylistRepository(db *sql.DB) PlaylistRepository {
-	return &playlistRepository{db: db}
+	return &playlistRepository{db: db, dialect: mysqlDialect}
 }
 
 func (r *playlistRepository) CreatePlaylist(ctx context.Context, playlist *model.Playlist) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
 	query := `
-		INSERT INTO playlists (id, name, host_id, created_at, updated_at, is_moderated)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO playlists (id, name, host_id, created_at, updated_at, is_moderated, vote_ordered)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, r.dialect.rebind(query),
 		playlist.ID,
 		playlist.Name,
 		playlist.HostID,
-		time.Now(),
-		time.Now(),
-		true, // Create new playlist as able to be moderated
+		now,
+		now,
+		playlist.IsModerated,
+		playlist.VoteOrdered,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`INSERT INTO playlist_members (playlist_id, host_id, role, added_at)
+		VALUES (?, ?, ?, ?)`),
+		playlist.ID,
+		playlist.HostID,
+		rbac.MemberOwner,
+		now,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *playlistRepository) GetPlaylist(ctx context.Context, id string) (*model.Playlist, error) {
 	query := `
-		SELECT id, name, host_id, created_at, updated_at, is_moderated
+		SELECT id, name, host_id, created_at, updated_at, is_moderated, vote_ordered
 		FROM playlists
 		WHERE id = ?
 	`
 	playlist := &model.Playlist{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, r.dialect.rebind(query), id).Scan(
 		&playlist.ID,
 		&playlist.Name,
 		&playlist.HostID,
 		&playlist.CreatedAt,
 		&playlist.UpdatedAt,
 		&playlist.IsModerated,
+		&playlist.VoteOrdered,
 	)
 	if err == sql.ErrNoRows {
 		return nil, errors.ErrPlaylistNotFound
@@ -70,13 +123,14 @@ func (r *playlistRepository) GetPlaylist(ctx context.Context, id string) (*model
 func (r *playlistRepository) UpdatePlaylist(ctx context.Context, playlist *model.Playlist) error {
 	query := `
 		UPDATE playlists
-		SET name = ?, updated_at = ?, is_moderated = ?
+		SET name = ?, updated_at = ?, is_moderated = ?, vote_ordered = ?
 		WHERE id = ?
 	`
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
 		playlist.Name,
 		time.Now(),
 		playlist.IsModerated,
+		playlist.VoteOrdered,
 		playlist.ID,
 	)
 	if err != nil {
@@ -94,7 +148,7 @@ func (r *playlistRepository) UpdatePlaylist(ctx context.Context, playlist *model
 
 func (r *playlistRepository) DeletePlaylist(ctx context.Context, id string) error {
 	query := `DELETE FROM playlists WHERE id = ?`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query), id)
 	if err != nil {
 		return err
 	}
@@ -110,11 +164,11 @@ func (r *playlistRepository) DeletePlaylist(ctx context.Context, id string) erro
 
 func (r *playlistRepository) GetPlaylistsByHost(ctx context.Context, hostID string) ([]*model.Playlist, error) {
 	query := `
-		SELECT id, name, host_id, created_at, updated_at, is_moderated
+		SELECT id, name, host_id, created_at, updated_at, is_moderated, vote_ordered
 		FROM playlists
 		WHERE host_id = ?
 	`
-	rows, err := r.db.QueryContext(ctx, query, hostID)
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), hostID)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +184,7 @@ func (r *playlistRepository) GetPlaylistsByHost(ctx context.Context, hostID stri
 			&playlist.CreatedAt,
 			&playlist.UpdatedAt,
 			&playlist.IsModerated,
+			&playlist.VoteOrdered,
 		)
 		if err != nil {
 			return nil, err
@@ -139,38 +194,138 @@ func (r *playlistRepository) GetPlaylistsByHost(ctx context.Context, hostID stri
 	return playlists, rows.Err()
 }
 
-func (r *playlistRepository) AddTrack(ctx context.Context, track *model.Playlist_Track) error {
+func (r *playlistRepository) ListPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error) {
+	return listPlaylistsByHost(ctx, r.db, r.dialect, hostID, filter, opts)
+}
+
+// playlistTrackColumns selects a playlist entry joined with its catalog
+// track and vote score, in the order scanned by scanPlaylistTrack.
+const playlistTrackColumns = `
+	pt.id, pt.playlist_id, pt.track_id, t.title, t.artist, t.duration,
+	pt.position, pt.added_at, pt.is_playing, pt.started_at,
+	(SELECT COALESCE(SUM(v.value), 0) FROM track_votes v
+		WHERE v.playlist_id = pt.playlist_id AND v.track_id = pt.id)
+`
+
+func scanPlaylistTrack(row rowScanner) (*model.Playlist_Track, error) {
+	track := &model.Playlist_Track{}
+	var startedAt sql.NullTime
+	err := row.Scan(
+		&track.ID,
+		&track.PlaylistID,
+		&track.TrackID,
+		&track.Title,
+		&track.Artist,
+		&track.Duration,
+		&track.Position,
+		&track.AddedAt,
+		&track.IsPlaying,
+		&startedAt,
+		&track.Score,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		track.StartedAt = &startedAt.Time
+	}
+	return track, nil
+}
+
+func (r *playlistRepository) GetTrack(ctx context.Context, id string) (*model.Track, error) {
 	query := `
-		INSERT INTO tracks (id, playlist_id, title, artist, duration, position, added_at, is_playing)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		SELECT id, title, artist, duration, created_at
+		FROM tracks
+		WHERE id = ?
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		track.ID,
-		track.PlaylistID,
+	track := &model.Track{}
+	err := r.db.QueryRowContext(ctx, r.dialect.rebind(query), id).Scan(
+		&track.ID,
+		&track.Title,
+		&track.Artist,
+		&track.Duration,
+		&track.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrTrackNotFound
+	}
+	return track, err
+}
+
+func (r *playlistRepository) AddTrack(ctx context.Context, track *model.Playlist_Track) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Catalog tracks are shared, so an existing one is kept as it is
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`INSERT INTO tracks (id, title, artist, duration, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		`+r.dialect.upsert([]string{"id"}, "id")),
+		track.TrackID,
 		track.Title,
 		track.Artist,
 		track.Duration,
+		track.AddedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`INSERT INTO playlist_tracks (id, playlist_id, track_id, position, added_at, is_playing)
+		VALUES (?, ?, ?, ?, ?, ?)`),
+		track.ID,
+		track.PlaylistID,
+		track.TrackID,
 		track.Position,
 		track.AddedAt,
 		track.IsPlaying,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *playlistRepository) RemoveTrack(ctx context.Context, playlistID, trackID string) error {
-	query := `DELETE FROM tracks WHERE playlist_id = ? AND id = ?`
-	result, err := r.db.ExecContext(ctx, query, playlistID, trackID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	rows, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	var position int
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind("SELECT position FROM playlist_tracks WHERE playlist_id = ? AND id = ?"+r.dialect.forUpdate()),
+		playlistID, trackID).Scan(&position)
+	if err == sql.ErrNoRows {
+		return errors.ErrTrackNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if rows == 0 {
-		return errors.ErrTrackNotFound
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind("DELETE FROM playlist_tracks WHERE playlist_id = ? AND id = ?"),
+		playlistID, trackID)
+	if err != nil {
+		return err
 	}
-	return nil
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`UPDATE playlist_tracks
+		SET position = position - 1
+		WHERE playlist_id = ? AND position > ?`),
+		playlistID, position)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *playlistRepository) UpdateTrackPosition(ctx context.Context, playlistID, trackID string, newPosition int) error {
@@ -183,8 +338,11 @@ func (r *playlistRepository) UpdateTrackPosition(ctx context.Context, playlistID
 	// Get current position
 	var currentPos int
 	err = tx.QueryRowContext(ctx,
-		"SELECT position FROM tracks WHERE playlist_id = ? AND id = ?",
+		r.dialect.rebind("SELECT position FROM playlist_tracks WHERE playlist_id = ? AND id = ?"+r.dialect.forUpdate()),
 		playlistID, trackID).Scan(&currentPos)
+	if err == sql.ErrNoRows {
+		return errors.ErrTrackNotFound
+	}
 	if err != nil {
 		return err
 	}
@@ -192,15 +350,15 @@ func (r *playlistRepository) UpdateTrackPosition(ctx context.Context, playlistID
 	// Update positions of other tracks
 	if currentPos < newPosition {
 		_, err = tx.ExecContext(ctx,
-			`UPDATE tracks 
-			SET position = position - 1 
-			WHERE playlist_id = ? AND position > ? AND position <= ?`,
+			r.dialect.rebind(`UPDATE playlist_tracks
+			SET position = position - 1
+			WHERE playlist_id = ? AND position > ? AND position <= ?`),
 			playlistID, currentPos, newPosition)
 	} else {
 		_, err = tx.ExecContext(ctx,
-			`UPDATE tracks 
-			SET position = position + 1 
-			WHERE playlist_id = ? AND position >= ? AND position < ?`,
+			r.dialect.rebind(`UPDATE playlist_tracks
+			SET position = position + 1
+			WHERE playlist_id = ? AND position >= ? AND position < ?`),
 			playlistID, newPosition, currentPos)
 	}
 	if err != nil {
@@ -209,7 +367,7 @@ func (r *playlistRepository) UpdateTrackPosition(ctx context.Context, playlistID
 
 	// Update position of target track
 	_, err = tx.ExecContext(ctx,
-		"UPDATE tracks SET position = ? WHERE playlist_id = ? AND id = ?",
+		r.dialect.rebind("UPDATE playlist_tracks SET position = ? WHERE playlist_id = ? AND id = ?"),
 		newPosition, playlistID, trackID)
 	if err != nil {
 		return err
@@ -218,24 +376,192 @@ func (r *playlistRepository) UpdateTrackPosition(ctx context.Context, playlistID
 	return tx.Commit()
 }
 
+func (r *playlistRepository) ReorderTracks(ctx context.Context, playlistID string, trackIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the playlist so positions can't move between reading and writing
+	var id string
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind("SELECT id FROM playlists WHERE id = ?"+r.dialect.forUpdate()),
+		playlistID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.ErrPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		r.dialect.rebind("SELECT id, position FROM playlist_tracks WHERE playlist_id = ?"),
+		playlistID)
+	if err != nil {
+		return err
+	}
+	positions := make(map[string]int)
+	for rows.Next() {
+		var trackID string
+		var position int
+		if err := rows.Scan(&trackID, &position); err != nil {
+			rows.Close()
+			return err
+		}
+		positions[trackID] = position
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ordered, slots := reorderSlots(positions, trackIDs)
+	for i, trackID := range ordered {
+		if positions[trackID] == slots[i] {
+			continue
+		}
+		_, err = tx.ExecContext(ctx,
+			r.dialect.rebind("UPDATE playlist_tracks SET position = ? WHERE playlist_id = ? AND id = ?"),
+			slots[i], playlistID, trackID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// reorderSlots returns the entries of trackIDs found in positions, in the
+// same order, and the positions they hold sorted ascending: entry i moves
+// to slot i.
+func reorderSlots(positions map[string]int, trackIDs []string) ([]string, []int) {
+	var ordered []string
+	var slots []int
+	for _, trackID := range trackIDs {
+		if position, ok := positions[trackID]; ok {
+			ordered = append(ordered, trackID)
+			slots = append(slots, position)
+		}
+	}
+	sort.Ints(slots)
+	return ordered, slots
+}
+
 func (r *playlistRepository) GetCurrentTrack(ctx context.Context, playlistID string) (*model.Playlist_Track, error) {
 	query := `
-		SELECT id, playlist_id, title, artist, duration, position, added_at, is_playing
-		FROM tracks
-		WHERE playlist_id = ? AND is_playing = true
+		SELECT ` + playlistTrackColumns + `
+		FROM playlist_tracks pt
+		INNER JOIN tracks t ON t.id = pt.track_id
+		WHERE pt.playlist_id = ? AND pt.is_playing = true
 		LIMIT 1
 	`
-	track := &model.Playlist_Track{}
-	err := r.db.QueryRowContext(ctx, query, playlistID).Scan(
-		&track.ID,
-		&track.PlaylistID,
-		&track.Title,
-		&track.Artist,
-		&track.Duration,
-		&track.Position,
-		&track.AddedAt,
-		&track.IsPlaying,
-	)
+	track, err := scanPlaylistTrack(r.db.QueryRowContext(ctx, r.dialect.rebind(query), playlistID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return track, err
+}
+
+func (r *playlistRepository) SetCurrentTrack(ctx context.Context, playlistID, trackID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the playlist so concurrent calls can't leave two tracks playing
+	var id string
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind("SELECT id FROM playlists WHERE id = ?"+r.dialect.forUpdate()),
+		playlistID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.ErrPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`UPDATE playlist_tracks
+		SET is_playing = false, started_at = NULL
+		WHERE playlist_id = ? AND is_playing = true`),
+		playlistID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		r.dialect.rebind(`UPDATE playlist_tracks
+		SET is_playing = true, started_at = ?
+		WHERE playlist_id = ? AND id = ?`),
+		time.Now(), playlistID, trackID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrTrackNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *playlistRepository) ClearCurrentTrack(ctx context.Context, playlistID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the playlist so a concurrent SetCurrentTrack can't slip a track
+	// in between
+	var id string
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind("SELECT id FROM playlists WHERE id = ?"+r.dialect.forUpdate()),
+		playlistID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.ErrPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.dialect.rebind(`UPDATE playlist_tracks
+		SET is_playing = false, started_at = NULL
+		WHERE playlist_id = ? AND is_playing = true`),
+		playlistID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *playlistRepository) GetAdjacentTrack(ctx context.Context, playlistID string, position int, forward bool) (*model.Playlist_Track, error) {
+	query := `
+		SELECT ` + playlistTrackColumns + `
+		FROM playlist_tracks pt
+		INNER JOIN tracks t ON t.id = pt.track_id
+		WHERE pt.playlist_id = ? AND pt.position > ?
+		ORDER BY pt.position ASC
+		LIMIT 1
+	`
+	if !forward {
+		query = `
+			SELECT ` + playlistTrackColumns + `
+			FROM playlist_tracks pt
+			INNER JOIN tracks t ON t.id = pt.track_id
+			WHERE pt.playlist_id = ? AND pt.position < ?
+			ORDER BY pt.position DESC
+			LIMIT 1
+		`
+	}
+	track, err := scanPlaylistTrack(r.db.QueryRowContext(ctx, r.dialect.rebind(query), playlistID, position))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -244,14 +570,13 @@ func (r *playlistRepository) GetCurrentTrack(ctx context.Context, playlistID str
 
 func (r *playlistRepository) GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.Playlist_Track, error) {
 	query := `
-		SELECT t.id, pt.playlist_id, t.title, t.artist, t.duration, pt.position, pt.added_at, pt.is_playing
+		SELECT ` + playlistTrackColumns + `
 		FROM playlist_tracks pt
-		INNER JOIN tracks t
-		ON t.id = pt.track_id 
-		WHERE playlist_id = ?
-		ORDER BY position;
+		INNER JOIN tracks t ON t.id = pt.track_id
+		WHERE pt.playlist_id = ?
+		ORDER BY pt.position
 	`
-	rows, err := r.db.QueryContext(ctx, query, playlistID)
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), playlistID)
 	if err != nil {
 		return nil, err
 	}
@@ -259,17 +584,7 @@ func (r *playlistRepository) GetPlaylistTracks(ctx context.Context, playlistID s
 
 	var tracks []*model.Playlist_Track
 	for rows.Next() {
-		track := &model.Playlist_Track{}
-		err := rows.Scan(
-			&track.ID,
-			&track.PlaylistID,
-			&track.Title,
-			&track.Artist,
-			&track.Duration,
-			&track.Position,
-			&track.AddedAt,
-			&track.IsPlaying,
-		)
+		track, err := scanPlaylistTrack(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -277,3 +592,7 @@ func (r *playlistRepository) GetPlaylistTracks(ctx context.Context, playlistID s
 	}
 	return tracks, rows.Err()
 }
+
+func (r *playlistRepository) ListPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error) {
+	return listPlaylistTracks(ctx, r.db, r.dialect, playlistID, filter, opts)
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	// RotateRefreshToken revokes the token with oldID and stores next as its
+	// replacement. It fails with ErrRefreshTokenReused if oldID was already
+	// revoked, which happens when two clients race with the same token.
+	RotateRefreshToken(ctx context.Context, oldID string, next *model.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+}
+
+type refreshTokenRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return r.insertRefreshToken(ctx, r.db, token)
+}
+
+func (r *refreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, host_id, family_id, token_hash, created_at, expires_at, revoked_at, replaced_by
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`
+	token := &model.RefreshToken{}
+	var revokedAt sql.NullTime
+	var replacedBy sql.NullString
+	err := r.db.QueryRowContext(ctx, r.dialect.rebind(query), tokenHash).Scan(
+		&token.ID,
+		&token.HostID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&revokedAt,
+		&replacedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.ReplacedBy = replacedBy.String
+	return token, nil
+}
+
+func (r *refreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, next *model.RefreshToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, r.dialect.rebind(
+		`UPDATE refresh_tokens
+		SET revoked_at = ?, replaced_by = ?
+		WHERE id = ? AND revoked_at IS NULL`),
+		time.Now(), next.ID, oldID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrRefreshTokenReused
+	}
+
+	if err := r.insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *refreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`),
+		time.Now(), familyID)
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (r *refreshTokenRepository) insertRefreshToken(ctx context.Context, db execer, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, host_id, family_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.ExecContext(ctx, r.dialect.rebind(query),
+		token.ID,
+		token.HostID,
+		token.FamilyID,
+		token.TokenHash,
+		token.CreatedAt,
+		token.ExpiresAt,
+	)
+	return err
+}
@@ -2,31 +2,80 @@ package repository
 
 import (
 	"database/sql"
-	"sync"
-
-	"github.com/dmarquinah/publist_backend/internal/model"
 )
 
 type Repository interface {
 	GetPlaylistRepository() PlaylistRepository
+	GetHostRepository() HostRepository
+	GetRefreshTokenRepository() RefreshTokenRepository
+	GetSongRequestRepository() SongRequestRepository
+	GetVoteRepository() VoteRepository
+	GetMemberRepository() MemberRepository
+	GetModerationRepository() ModerationRepository
 	PlaylistRepository
 }
 
+// NewRepository returns the MySQL-backed Repository.
 func NewRepository(db *sql.DB) Repository {
-	playlistRepository := NewPlaylistRepository(db)
+	return newSQLRepository(db, mysqlDialect)
+}
+
+// NewPostgresRepository returns the Postgres-backed Repository.
+func NewPostgresRepository(db *sql.DB) Repository {
+	return newSQLRepository(db, postgresDialect)
+}
+
+// NewSQLiteRepository returns the SQLite-backed Repository.
+func NewSQLiteRepository(db *sql.DB) Repository {
+	return newSQLRepository(db, sqliteDialect)
+}
+
+func newSQLRepository(db *sql.DB, d dialect) Repository {
 	return &repository{
-		items:              make(map[string]*model.Item),
-		PlaylistRepository: playlistRepository,
-		mu:                 &sync.RWMutex{},
+		PlaylistRepository:   &tracedPlaylistRepository{next: &playlistRepository{db: db, dialect: d}, system: d.system},
+		hostRepository:       &hostRepository{db: db, dialect: d},
+		tokenRepository:      &refreshTokenRepository{db: db, dialect: d},
+		requestRepository:    &songRequestRepository{db: db, dialect: d},
+		voteRepository:       &voteRepository{db: db, dialect: d},
+		memberRepository:     &memberRepository{db: db, dialect: d},
+		moderationRepository: &moderationRepository{db: db, dialect: d},
 	}
 }
 
 type repository struct {
-	items map[string]*model.Item
-	mu    *sync.RWMutex
+	hostRepository       HostRepository
+	tokenRepository      RefreshTokenRepository
+	requestRepository    SongRequestRepository
+	voteRepository       VoteRepository
+	memberRepository     MemberRepository
+	moderationRepository ModerationRepository
 	PlaylistRepository
 }
 
 func (r *repository) GetPlaylistRepository() PlaylistRepository {
 	return r.PlaylistRepository
 }
+
+func (r *repository) GetHostRepository() HostRepository {
+	return r.hostRepository
+}
+
+func (r *repository) GetRefreshTokenRepository() RefreshTokenRepository {
+	return r.tokenRepository
+}
+
+func (r *repository) GetSongRequestRepository() SongRequestRepository {
+	return r.requestRepository
+}
+
+func (r *repository) GetVoteRepository() VoteRepository {
+	return r.voteRepository
+}
+
+func (r *repository) GetMemberRepository() MemberRepository {
+	return r.memberRepository
+}
+
+func (r *repository) GetModerationRepository() ModerationRepository {
+	return r.moderationRepository
+}
@@ -0,0 +1,191 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/google/uuid"
+)
+
+// RunMemberRepository runs the MemberRepository tests, along with the host
+// role they depend on.
+func RunMemberRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f *fixture)
+	}{
+		{"HostRole", testHostRole},
+		{"CreatePlaylistAddsOwner", testCreatePlaylistAddsOwner},
+		{"AddAndGetPlaylistMembers", testAddAndGetPlaylistMembers},
+		{"AddPlaylistMemberTwice", testAddPlaylistMemberTwice},
+		{"GetPlaylistMemberRole", testGetPlaylistMemberRole},
+		{"RemovePlaylistMember", testRemovePlaylistMember},
+		{"RemovePlaylistMemberNotFound", testRemovePlaylistMemberNotFound},
+		{"DeletePlaylistRemovesMembers", testDeletePlaylistRemovesMembers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &fixture{ctx: context.Background(), repo: newRepo(t)})
+		})
+	}
+}
+
+func (f *fixture) addMember(t *testing.T, playlistID, hostID string, role rbac.MemberRole) {
+	t.Helper()
+	member := &model.Member{
+		PlaylistID: playlistID,
+		HostID:     hostID,
+		Role:       role,
+		AddedAt:    time.Now(),
+	}
+	if err := f.repo.GetMemberRepository().AddPlaylistMember(f.ctx, member); err != nil {
+		t.Fatalf("AddPlaylistMember: %v", err)
+	}
+}
+
+func (f *fixture) members(t *testing.T, playlistID string) []*model.Member {
+	t.Helper()
+	members, err := f.repo.GetMemberRepository().GetPlaylistMembers(f.ctx, playlistID)
+	if err != nil {
+		t.Fatalf("GetPlaylistMembers: %v", err)
+	}
+	return members
+}
+
+func testHostRole(t *testing.T, f *fixture) {
+	host := f.hostWithRole(t, rbac.RoleStaff)
+
+	got, err := f.repo.GetHostRepository().GetHost(f.ctx, host.ID)
+	if err != nil {
+		t.Fatalf("GetHost: %v", err)
+	}
+	if got.Role != rbac.RoleStaff {
+		t.Errorf("Role = %q, want %q", got.Role, rbac.RoleStaff)
+	}
+}
+
+func testCreatePlaylistAddsOwner(t *testing.T, f *fixture) {
+	host := f.host(t)
+	playlist := f.playlist(t, host.ID)
+
+	members := f.members(t, playlist.ID)
+	if len(members) != 1 {
+		t.Fatalf("got %d members, want 1", len(members))
+	}
+	owner := members[0]
+	if owner.HostID != host.ID || owner.Role != rbac.MemberOwner ||
+		owner.Name != host.Name || owner.Email != host.Email || owner.AddedAt.IsZero() {
+		t.Errorf("owner = %+v, want %s as owner", owner, host.ID)
+	}
+}
+
+func testAddAndGetPlaylistMembers(t *testing.T, f *fixture) {
+	owner := f.host(t)
+	playlist := f.playlist(t, owner.ID)
+	editor := f.hostWithRole(t, rbac.RoleStaff)
+	viewer := f.hostWithRole(t, rbac.RoleViewer)
+	f.addMember(t, playlist.ID, editor.ID, rbac.MemberEditor)
+	f.addMember(t, playlist.ID, viewer.ID, rbac.MemberViewer)
+
+	members := f.members(t, playlist.ID)
+	if len(members) != 3 {
+		t.Fatalf("got %d members, want 3", len(members))
+	}
+	want := []struct {
+		hostID string
+		role   rbac.MemberRole
+	}{
+		{owner.ID, rbac.MemberOwner},
+		{editor.ID, rbac.MemberEditor},
+		{viewer.ID, rbac.MemberViewer},
+	}
+	for i, w := range want {
+		if members[i].HostID != w.hostID || members[i].Role != w.role {
+			t.Errorf("members[%d] = %s as %s, want %s as %s", i, members[i].HostID, members[i].Role, w.hostID, w.role)
+		}
+	}
+	if members[1].PlaylistID != playlist.ID || members[1].Name != editor.Name || members[1].Email != editor.Email {
+		t.Errorf("member = %+v, want the details of %s", members[1], editor.ID)
+	}
+}
+
+func testAddPlaylistMemberTwice(t *testing.T, f *fixture) {
+	owner := f.host(t)
+	playlist := f.playlist(t, owner.ID)
+	editor := f.hostWithRole(t, rbac.RoleStaff)
+	f.addMember(t, playlist.ID, editor.ID, rbac.MemberEditor)
+
+	for _, hostID := range []string{editor.ID, owner.ID} {
+		err := f.repo.GetMemberRepository().AddPlaylistMember(f.ctx, &model.Member{
+			PlaylistID: playlist.ID,
+			HostID:     hostID,
+			Role:       rbac.MemberViewer,
+			AddedAt:    time.Now(),
+		})
+		assertErr(t, err, errorsmsg.ErrAlreadyMember)
+	}
+}
+
+func testGetPlaylistMemberRole(t *testing.T, f *fixture) {
+	owner := f.host(t)
+	playlist := f.playlist(t, owner.ID)
+	other := f.playlist(t, f.host(t).ID)
+	editor := f.hostWithRole(t, rbac.RoleStaff)
+	f.addMember(t, playlist.ID, editor.ID, rbac.MemberEditor)
+
+	members := f.repo.GetMemberRepository()
+	for _, tt := range []struct {
+		playlistID, hostID string
+		want               rbac.MemberRole
+	}{
+		{playlist.ID, owner.ID, rbac.MemberOwner},
+		{playlist.ID, editor.ID, rbac.MemberEditor},
+	} {
+		got, err := members.GetPlaylistMemberRole(f.ctx, tt.playlistID, tt.hostID)
+		if err != nil {
+			t.Fatalf("GetPlaylistMemberRole: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GetPlaylistMemberRole(%s, %s) = %q, want %q", tt.playlistID, tt.hostID, got, tt.want)
+		}
+	}
+
+	_, err := members.GetPlaylistMemberRole(f.ctx, other.ID, editor.ID)
+	assertErr(t, err, errorsmsg.ErrMemberNotFound)
+}
+
+func testRemovePlaylistMember(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	editor := f.hostWithRole(t, rbac.RoleStaff)
+	f.addMember(t, playlist.ID, editor.ID, rbac.MemberEditor)
+
+	members := f.repo.GetMemberRepository()
+	if err := members.RemovePlaylistMember(f.ctx, playlist.ID, editor.ID); err != nil {
+		t.Fatalf("RemovePlaylistMember: %v", err)
+	}
+	_, err := members.GetPlaylistMemberRole(f.ctx, playlist.ID, editor.ID)
+	assertErr(t, err, errorsmsg.ErrMemberNotFound)
+}
+
+func testRemovePlaylistMemberNotFound(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+
+	err := f.repo.GetMemberRepository().RemovePlaylistMember(f.ctx, playlist.ID, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrMemberNotFound)
+}
+
+func testDeletePlaylistRemovesMembers(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	f.addMember(t, playlist.ID, f.hostWithRole(t, rbac.RoleStaff).ID, rbac.MemberEditor)
+
+	if err := f.repo.DeletePlaylist(f.ctx, playlist.ID); err != nil {
+		t.Fatalf("DeletePlaylist: %v", err)
+	}
+	if members := f.members(t, playlist.ID); len(members) != 0 {
+		t.Errorf("deleted playlist still has %d members", len(members))
+	}
+}
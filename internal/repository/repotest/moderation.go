@@ -0,0 +1,325 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/google/uuid"
+)
+
+// RunModerationRepository runs the ModerationRepository tests. The database
+// may be shared, so listings are filtered by playlist wherever they can be.
+func RunModerationRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f *fixture)
+	}{
+		{"CreateAndGetBlockRule", testCreateAndGetBlockRule},
+		{"GetBlockRules", testGetBlockRules},
+		{"DeleteBlockRule", testDeleteBlockRule},
+		{"BlockRuleNotFound", testBlockRuleNotFound},
+		{"DeletePlaylistRemovesBlockRules", testDeletePlaylistRemovesBlockRules},
+		{"CreateAndGetModerationFlag", testCreateAndGetModerationFlag},
+		{"ReviewModerationFlag", testReviewModerationFlag},
+		{"ReviewModerationFlagTwice", testReviewModerationFlagTwice},
+		{"ListModerationFlags", testListModerationFlags},
+		{"ListAuditEntries", testListAuditEntries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &fixture{ctx: context.Background(), repo: newRepo(t)})
+		})
+	}
+}
+
+func (f *fixture) blockRule(t *testing.T, playlistID string, kind model.BlockKind, pattern string) *model.BlockRule {
+	t.Helper()
+	rule := &model.BlockRule{
+		ID:         uuid.New().String(),
+		PlaylistID: playlistID,
+		Kind:       kind,
+		Pattern:    pattern,
+		Action:     model.BlockReject,
+		CreatedBy:  uuid.New().String(),
+		CreatedAt:  time.Now(),
+	}
+	if err := f.repo.GetModerationRepository().CreateBlockRule(f.ctx, rule); err != nil {
+		t.Fatalf("CreateBlockRule: %v", err)
+	}
+	return rule
+}
+
+func (f *fixture) moderationFlag(t *testing.T, playlistID string, createdAt time.Time) *model.ModerationFlag {
+	t.Helper()
+	flag := &model.ModerationFlag{
+		ID:         uuid.New().String(),
+		PlaylistID: playlistID,
+		EntryID:    uuid.New().String(),
+		Title:      "Title",
+		Artist:     "Artist",
+		RuleID:     uuid.New().String(),
+		Kind:       model.BlockWord,
+		Pattern:    "title",
+		Status:     model.FlagPending,
+		CreatedAt:  createdAt,
+	}
+	if err := f.repo.GetModerationRepository().CreateModerationFlag(f.ctx, flag); err != nil {
+		t.Fatalf("CreateModerationFlag: %v", err)
+	}
+	return flag
+}
+
+func ruleIDs(rules []*model.BlockRule) map[string]bool {
+	ids := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		ids[rule.ID] = true
+	}
+	return ids
+}
+
+func testCreateAndGetBlockRule(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	rule := f.blockRule(t, playlist.ID, model.BlockArtist, "Some Artist")
+
+	got, err := f.repo.GetModerationRepository().GetBlockRule(f.ctx, rule.ID)
+	if err != nil {
+		t.Fatalf("GetBlockRule: %v", err)
+	}
+	if got.PlaylistID != playlist.ID || got.Kind != rule.Kind || got.Pattern != rule.Pattern ||
+		got.Action != rule.Action || got.CreatedBy != rule.CreatedBy || got.CreatedAt.IsZero() {
+		t.Errorf("GetBlockRule = %+v, want %+v", got, rule)
+	}
+}
+
+func testGetBlockRules(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	first := f.blockRule(t, playlist.ID, model.BlockWord, "first")
+	second := f.blockRule(t, playlist.ID, model.BlockTitle, "second")
+	global := f.blockRule(t, "", model.BlockWord, "global")
+	moderation := f.repo.GetModerationRepository()
+	t.Cleanup(func() { moderation.DeleteBlockRule(f.ctx, global.ID) })
+
+	rules, err := moderation.GetBlockRules(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetBlockRules: %v", err)
+	}
+	if len(rules) != 2 || rules[0].ID != first.ID || rules[1].ID != second.ID {
+		t.Errorf("playlist rules = %v, want %s then %s", ruleIDs(rules), first.ID, second.ID)
+	}
+
+	rules, err = moderation.GetBlockRules(f.ctx, "")
+	if err != nil {
+		t.Fatalf("GetBlockRules: %v", err)
+	}
+	ids := ruleIDs(rules)
+	if !ids[global.ID] || ids[first.ID] || ids[second.ID] {
+		t.Errorf("global rules = %v, want %s and no playlist rules", ids, global.ID)
+	}
+	for _, rule := range rules {
+		if rule.ID == global.ID && rule.PlaylistID != "" {
+			t.Errorf("global rule has PlaylistID %q", rule.PlaylistID)
+		}
+	}
+}
+
+func testDeleteBlockRule(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	rule := f.blockRule(t, playlist.ID, model.BlockWord, "word")
+
+	moderation := f.repo.GetModerationRepository()
+	if err := moderation.DeleteBlockRule(f.ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteBlockRule: %v", err)
+	}
+	_, err := moderation.GetBlockRule(f.ctx, rule.ID)
+	assertErr(t, err, errorsmsg.ErrBlockRuleNotFound)
+}
+
+func testBlockRuleNotFound(t *testing.T, f *fixture) {
+	moderation := f.repo.GetModerationRepository()
+
+	_, err := moderation.GetBlockRule(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrBlockRuleNotFound)
+	err = moderation.DeleteBlockRule(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrBlockRuleNotFound)
+}
+
+func testDeletePlaylistRemovesBlockRules(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	rule := f.blockRule(t, playlist.ID, model.BlockWord, "word")
+	flag := f.moderationFlag(t, playlist.ID, time.Now())
+
+	if err := f.repo.DeletePlaylist(f.ctx, playlist.ID); err != nil {
+		t.Fatalf("DeletePlaylist: %v", err)
+	}
+	moderation := f.repo.GetModerationRepository()
+	_, err := moderation.GetBlockRule(f.ctx, rule.ID)
+	assertErr(t, err, errorsmsg.ErrBlockRuleNotFound)
+	_, err = moderation.GetModerationFlag(f.ctx, flag.ID)
+	assertErr(t, err, errorsmsg.ErrModerationFlagNotFound)
+}
+
+func testCreateAndGetModerationFlag(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	flag := f.moderationFlag(t, playlist.ID, time.Now())
+
+	moderation := f.repo.GetModerationRepository()
+	got, err := moderation.GetModerationFlag(f.ctx, flag.ID)
+	if err != nil {
+		t.Fatalf("GetModerationFlag: %v", err)
+	}
+	if got.PlaylistID != playlist.ID || got.EntryID != flag.EntryID || got.Title != flag.Title ||
+		got.Artist != flag.Artist || got.RuleID != flag.RuleID || got.Kind != flag.Kind ||
+		got.Pattern != flag.Pattern || got.Status != model.FlagPending ||
+		got.ReviewedBy != "" || got.ReviewedAt != nil {
+		t.Errorf("GetModerationFlag = %+v, want %+v", got, flag)
+	}
+
+	_, err = moderation.GetModerationFlag(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrModerationFlagNotFound)
+}
+
+func testReviewModerationFlag(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	flag := f.moderationFlag(t, playlist.ID, time.Now())
+
+	reviewedAt := time.Now()
+	flag.Status = model.FlagRemoved
+	flag.ReviewedBy = uuid.New().String()
+	flag.ReviewedAt = &reviewedAt
+
+	moderation := f.repo.GetModerationRepository()
+	if err := moderation.ReviewModerationFlag(f.ctx, flag); err != nil {
+		t.Fatalf("ReviewModerationFlag: %v", err)
+	}
+	got, err := moderation.GetModerationFlag(f.ctx, flag.ID)
+	if err != nil {
+		t.Fatalf("GetModerationFlag: %v", err)
+	}
+	if got.Status != model.FlagRemoved || got.ReviewedBy != flag.ReviewedBy || got.ReviewedAt == nil {
+		t.Errorf("reviewed flag = %+v, want removed by %s", got, flag.ReviewedBy)
+	}
+}
+
+func testReviewModerationFlagTwice(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	flag := f.moderationFlag(t, playlist.ID, time.Now())
+
+	moderation := f.repo.GetModerationRepository()
+	flag.Status = model.FlagApproved
+	if err := moderation.ReviewModerationFlag(f.ctx, flag); err != nil {
+		t.Fatalf("ReviewModerationFlag: %v", err)
+	}
+	flag.Status = model.FlagRemoved
+	err := moderation.ReviewModerationFlag(f.ctx, flag)
+	assertErr(t, err, errorsmsg.ErrModerationFlagReviewed)
+
+	got, err := moderation.GetModerationFlag(f.ctx, flag.ID)
+	if err != nil {
+		t.Fatalf("GetModerationFlag: %v", err)
+	}
+	if got.Status != model.FlagApproved {
+		t.Errorf("Status = %q, want %q", got.Status, model.FlagApproved)
+	}
+}
+
+func testListModerationFlags(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	other := f.playlist(t, f.host(t).ID)
+	start := time.Now().Add(-time.Hour)
+	var flags []*model.ModerationFlag
+	for i := 0; i < 3; i++ {
+		flags = append(flags, f.moderationFlag(t, playlist.ID, start.Add(time.Duration(i)*time.Minute)))
+	}
+	f.moderationFlag(t, other.ID, start)
+
+	flags[1].Status = model.FlagApproved
+	if err := f.repo.GetModerationRepository().ReviewModerationFlag(f.ctx, flags[1]); err != nil {
+		t.Fatalf("ReviewModerationFlag: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter model.FlagFilter
+		sort   string
+		want   []string
+	}{
+		{"oldest first", model.FlagFilter{PlaylistID: playlist.ID}, "", []string{flags[0].ID, flags[1].ID, flags[2].ID}},
+		{"newest first", model.FlagFilter{PlaylistID: playlist.ID}, "-created_at", []string{flags[2].ID, flags[1].ID, flags[0].ID}},
+		{"pending", model.FlagFilter{PlaylistID: playlist.ID, Status: model.FlagPending}, "", []string{flags[0].ID, flags[2].ID}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			opts := model.ListOptions{Limit: 2, Sort: tt.sort}
+			for pages := 0; ; pages++ {
+				if pages > 10 {
+					t.Fatal("ListModerationFlags doesn't stop paging")
+				}
+				page, err := f.repo.GetModerationRepository().ListModerationFlags(f.ctx, tt.filter, opts)
+				if err != nil {
+					t.Fatalf("ListModerationFlags: %v", err)
+				}
+				for _, flag := range page.Items {
+					got = append(got, flag.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				opts.Cursor = page.NextCursor
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func testListAuditEntries(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	admin := uuid.New().String()
+	start := time.Now().Add(-time.Hour)
+	var entries []*model.AuditEntry
+	for i, actorID := range []string{admin, "", admin} {
+		entry := &model.AuditEntry{
+			ID:         uuid.New().String(),
+			ActorID:    actorID,
+			Action:     model.AuditTrackRejected,
+			PlaylistID: playlist.ID,
+			TargetID:   uuid.New().String(),
+			Detail:     `"Title" by "Artist"`,
+			CreatedAt:  start.Add(time.Duration(i) * time.Minute),
+		}
+		if err := f.repo.GetModerationRepository().AddAuditEntry(f.ctx, entry); err != nil {
+			t.Fatalf("AddAuditEntry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	moderation := f.repo.GetModerationRepository()
+	page, err := moderation.ListAuditEntries(f.ctx, model.AuditFilter{PlaylistID: playlist.ID}, model.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	if len(page.Items) != 3 || page.Items[0].ID != entries[2].ID || page.Items[2].ID != entries[0].ID {
+		t.Fatalf("got %d entries, want the 3 newest first", len(page.Items))
+	}
+	got := page.Items[1]
+	if got.ActorID != "" || got.Action != model.AuditTrackRejected || got.TargetID != entries[1].TargetID || got.Detail != entries[1].Detail {
+		t.Errorf("entry = %+v, want %+v", got, entries[1])
+	}
+
+	page, err = moderation.ListAuditEntries(f.ctx, model.AuditFilter{PlaylistID: playlist.ID, ActorID: admin}, model.ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != entries[2].ID || page.NextCursor == "" {
+		t.Errorf("first page by actor = %+v, want %s and a cursor", page, entries[2].ID)
+	}
+}
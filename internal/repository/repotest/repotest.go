@@ -0,0 +1,770 @@
+// Package repotest is a conformance suite for repository implementations.
+// Every backend runs the same tests so they stay interchangeable:
+//
+//	func TestMemoryRepository(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repository.Repository {
+//			return repository.NewMemoryRepository()
+//		})
+//	}
+//
+// Tests create their own hosts and playlists with fresh IDs, so a backend
+// may share one database across tests without cleaning it up.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Factory returns the repository under test.
+type Factory func(t *testing.T) repository.Repository
+
+// Run runs the whole suite against the repositories returned by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("PlaylistRepository", func(t *testing.T) {
+		RunPlaylistRepository(t, newRepo)
+	})
+	t.Run("MemberRepository", func(t *testing.T) {
+		RunMemberRepository(t, newRepo)
+	})
+	t.Run("ModerationRepository", func(t *testing.T) {
+		RunModerationRepository(t, newRepo)
+	})
+	t.Run("SongRequestRepository", func(t *testing.T) {
+		RunSongRequestRepository(t, newRepo)
+	})
+}
+
+// RunPlaylistRepository runs the PlaylistRepository tests.
+func RunPlaylistRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f *fixture)
+	}{
+		{"CreateAndGetPlaylist", testCreateAndGetPlaylist},
+		{"GetPlaylistNotFound", testGetPlaylistNotFound},
+		{"UpdatePlaylist", testUpdatePlaylist},
+		{"UpdatePlaylistNotFound", testUpdatePlaylistNotFound},
+		{"DeletePlaylist", testDeletePlaylist},
+		{"DeletePlaylistNotFound", testDeletePlaylistNotFound},
+		{"GetPlaylistsByHost", testGetPlaylistsByHost},
+		{"AddTrack", testAddTrack},
+		{"AddTrackReusesCatalogTrack", testAddTrackReusesCatalogTrack},
+		{"GetTrackNotFound", testGetTrackNotFound},
+		{"RemoveTrack", testRemoveTrack},
+		{"RemoveTrackNotFound", testRemoveTrackNotFound},
+		{"UpdateTrackPositionMoveUp", testUpdateTrackPositionMoveUp},
+		{"UpdateTrackPositionMoveDown", testUpdateTrackPositionMoveDown},
+		{"UpdateTrackPositionSamePosition", testUpdateTrackPositionSamePosition},
+		{"UpdateTrackPositionNotFound", testUpdateTrackPositionNotFound},
+		{"ReorderTracks", testReorderTracks},
+		{"ReorderTracksSkipsRemoved", testReorderTracksSkipsRemoved},
+		{"ReorderTracksNotFound", testReorderTracksNotFound},
+		{"CurrentTrack", testCurrentTrack},
+		{"SetCurrentTrackNotFound", testSetCurrentTrackNotFound},
+		{"GetAdjacentTrack", testGetAdjacentTrack},
+		{"PlaylistTracksScore", testPlaylistTracksScore},
+		{"ListPlaylistsByHost", testListPlaylistsByHost},
+		{"ListPlaylistsByHostFilter", testListPlaylistsByHostFilter},
+		{"ListPlaylistTracks", testListPlaylistTracks},
+		{"ListPlaylistTracksFilter", testListPlaylistTracksFilter},
+		{"ListInvalidOptions", testListInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &fixture{ctx: context.Background(), repo: newRepo(t)})
+		})
+	}
+}
+
+type fixture struct {
+	ctx  context.Context
+	repo repository.Repository
+}
+
+func (f *fixture) host(t *testing.T) *model.Host {
+	t.Helper()
+	return f.hostWithRole(t, rbac.RoleHost)
+}
+
+func (f *fixture) hostWithRole(t *testing.T, role rbac.Role) *model.Host {
+	t.Helper()
+	id := uuid.New().String()
+	host := &model.Host{
+		ID:           id,
+		Name:         "Host " + id[:8],
+		Email:        id + "@example.com",
+		PasswordHash: "hash",
+		Role:         role,
+		CreatedAt:    time.Now(),
+		IsActive:     true,
+	}
+	if err := f.repo.GetHostRepository().CreateHost(f.ctx, host); err != nil {
+		t.Fatalf("CreateHost: %v", err)
+	}
+	return host
+}
+
+func (f *fixture) playlist(t *testing.T, hostID string) *model.Playlist {
+	t.Helper()
+	playlist := &model.Playlist{
+		ID:     uuid.New().String(),
+		Name:   "Evening",
+		HostID: hostID,
+	}
+	if err := f.repo.CreatePlaylist(f.ctx, playlist); err != nil {
+		t.Fatalf("CreatePlaylist: %v", err)
+	}
+	return playlist
+}
+
+// tracks adds n tracks titled "1".."n" at positions 1..n.
+func (f *fixture) tracks(t *testing.T, playlistID string, n int) []*model.Playlist_Track {
+	t.Helper()
+	tracks := make([]*model.Playlist_Track, n)
+	for i := range tracks {
+		tracks[i] = &model.Playlist_Track{
+			ID:         uuid.New().String(),
+			PlaylistID: playlistID,
+			TrackID:    uuid.New().String(),
+			Title:      fmt.Sprint(i + 1),
+			Artist:     "Artist",
+			Duration:   180,
+			Position:   i + 1,
+			AddedAt:    time.Now(),
+		}
+		if err := f.repo.AddTrack(f.ctx, tracks[i]); err != nil {
+			t.Fatalf("AddTrack: %v", err)
+		}
+	}
+	return tracks
+}
+
+// assertOrder checks the playlist's titles in position order, and that
+// positions run 1..n without gaps.
+func (f *fixture) assertOrder(t *testing.T, playlistID string, titles ...string) {
+	t.Helper()
+	tracks, err := f.repo.GetPlaylistTracks(f.ctx, playlistID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	got := make([]string, len(tracks))
+	for i, track := range tracks {
+		got[i] = track.Title
+		if track.Position != i+1 {
+			t.Errorf("track %q at position %d, want %d", track.Title, track.Position, i+1)
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(titles) {
+		t.Errorf("order = %v, want %v", got, titles)
+	}
+}
+
+func assertErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("err = %v, want %v", err, want)
+	}
+}
+
+func testCreateAndGetPlaylist(t *testing.T, f *fixture) {
+	host := f.host(t)
+	playlist := &model.Playlist{
+		ID:          uuid.New().String(),
+		Name:        "Evening",
+		HostID:      host.ID,
+		IsModerated: true,
+		VoteOrdered: true,
+	}
+	if err := f.repo.CreatePlaylist(f.ctx, playlist); err != nil {
+		t.Fatalf("CreatePlaylist: %v", err)
+	}
+
+	got, err := f.repo.GetPlaylist(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylist: %v", err)
+	}
+	if got.ID != playlist.ID || got.Name != "Evening" || got.HostID != host.ID || !got.IsModerated || !got.VoteOrdered {
+		t.Errorf("GetPlaylist = %+v, want %+v", got, playlist)
+	}
+	if got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+		t.Errorf("timestamps not set: %+v", got)
+	}
+}
+
+func testGetPlaylistNotFound(t *testing.T, f *fixture) {
+	_, err := f.repo.GetPlaylist(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
+
+func testUpdatePlaylist(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	playlist.Name = "Late night"
+	playlist.VoteOrdered = true
+	if err := f.repo.UpdatePlaylist(f.ctx, playlist); err != nil {
+		t.Fatalf("UpdatePlaylist: %v", err)
+	}
+
+	got, err := f.repo.GetPlaylist(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylist: %v", err)
+	}
+	if got.Name != "Late night" || !got.VoteOrdered {
+		t.Errorf("GetPlaylist = %+v, want updated name and vote ordering", got)
+	}
+}
+
+func testUpdatePlaylistNotFound(t *testing.T, f *fixture) {
+	err := f.repo.UpdatePlaylist(f.ctx, &model.Playlist{ID: uuid.New().String(), Name: "Missing"})
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
+
+func testDeletePlaylist(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	f.tracks(t, playlist.ID, 2)
+
+	if err := f.repo.DeletePlaylist(f.ctx, playlist.ID); err != nil {
+		t.Fatalf("DeletePlaylist: %v", err)
+	}
+	_, err := f.repo.GetPlaylist(f.ctx, playlist.ID)
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+
+	tracks, err := f.repo.GetPlaylistTracks(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	if len(tracks) != 0 {
+		t.Errorf("deleted playlist still has %d tracks", len(tracks))
+	}
+}
+
+func testDeletePlaylistNotFound(t *testing.T, f *fixture) {
+	err := f.repo.DeletePlaylist(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
+
+func testGetPlaylistsByHost(t *testing.T, f *fixture) {
+	host := f.host(t)
+	first := f.playlist(t, host.ID)
+	second := f.playlist(t, host.ID)
+	f.playlist(t, f.host(t).ID)
+
+	playlists, err := f.repo.GetPlaylistsByHost(f.ctx, host.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistsByHost: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, playlist := range playlists {
+		ids[playlist.ID] = true
+	}
+	if len(playlists) != 2 || !ids[first.ID] || !ids[second.ID] {
+		t.Errorf("GetPlaylistsByHost = %d playlists %v, want %s and %s", len(playlists), ids, first.ID, second.ID)
+	}
+}
+
+func testAddTrack(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	added := f.tracks(t, playlist.ID, 2)
+
+	tracks, err := f.repo.GetPlaylistTracks(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	if len(tracks) != 2 {
+		t.Fatalf("got %d tracks, want 2", len(tracks))
+	}
+	for i, track := range tracks {
+		want := added[i]
+		if track.ID != want.ID || track.PlaylistID != playlist.ID || track.TrackID != want.TrackID ||
+			track.Title != want.Title || track.Artist != want.Artist || track.Duration != want.Duration ||
+			track.Position != want.Position || track.IsPlaying || track.StartedAt != nil || track.Score != 0 {
+			t.Errorf("track %d = %+v, want %+v", i, track, want)
+		}
+	}
+
+	catalog, err := f.repo.GetTrack(f.ctx, added[0].TrackID)
+	if err != nil {
+		t.Fatalf("GetTrack: %v", err)
+	}
+	if catalog.ID != added[0].TrackID || catalog.Title != "1" || catalog.Artist != "Artist" || catalog.Duration != 180 {
+		t.Errorf("GetTrack = %+v, want catalog entry of %+v", catalog, added[0])
+	}
+}
+
+func testAddTrackReusesCatalogTrack(t *testing.T, f *fixture) {
+	host := f.host(t)
+	first := f.playlist(t, host.ID)
+	second := f.playlist(t, host.ID)
+	original := f.tracks(t, first.ID, 1)[0]
+
+	// The same catalog track, added again with different details, keeps
+	// the catalog's title
+	again := &model.Playlist_Track{
+		ID:         uuid.New().String(),
+		PlaylistID: second.ID,
+		TrackID:    original.TrackID,
+		Title:      "Renamed",
+		Artist:     original.Artist,
+		Duration:   original.Duration,
+		Position:   1,
+		AddedAt:    time.Now(),
+	}
+	if err := f.repo.AddTrack(f.ctx, again); err != nil {
+		t.Fatalf("AddTrack: %v", err)
+	}
+
+	tracks, err := f.repo.GetPlaylistTracks(f.ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	if len(tracks) != 1 || tracks[0].TrackID != original.TrackID || tracks[0].Title != original.Title {
+		t.Errorf("GetPlaylistTracks = %+v, want one entry of catalog track %s titled %q", tracks, original.TrackID, original.Title)
+	}
+}
+
+func testGetTrackNotFound(t *testing.T, f *fixture) {
+	_, err := f.repo.GetTrack(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrTrackNotFound)
+}
+
+func testRemoveTrack(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 3)
+
+	if err := f.repo.RemoveTrack(f.ctx, playlist.ID, tracks[1].ID); err != nil {
+		t.Fatalf("RemoveTrack: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "1", "3")
+
+	// The catalog track outlives the entry
+	if _, err := f.repo.GetTrack(f.ctx, tracks[1].TrackID); err != nil {
+		t.Errorf("GetTrack after RemoveTrack: %v", err)
+	}
+}
+
+func testRemoveTrackNotFound(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	other := f.playlist(t, playlist.HostID)
+	tracks := f.tracks(t, other.ID, 1)
+
+	err := f.repo.RemoveTrack(f.ctx, playlist.ID, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrTrackNotFound)
+
+	// An entry of another playlist is not found either
+	err = f.repo.RemoveTrack(f.ctx, playlist.ID, tracks[0].ID)
+	assertErr(t, err, errorsmsg.ErrTrackNotFound)
+	f.assertOrder(t, other.ID, "1")
+}
+
+func testUpdateTrackPositionMoveUp(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 4)
+
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[3].ID, 2); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "1", "4", "2", "3")
+
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[2].ID, 1); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "3", "1", "4", "2")
+}
+
+func testUpdateTrackPositionMoveDown(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 4)
+
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[0].ID, 3); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "2", "3", "1", "4")
+
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[1].ID, 4); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "3", "1", "4", "2")
+}
+
+func testUpdateTrackPositionSamePosition(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 3)
+
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[1].ID, 2); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "1", "2", "3")
+}
+
+func testUpdateTrackPositionNotFound(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	f.tracks(t, playlist.ID, 3)
+
+	err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, uuid.New().String(), 1)
+	assertErr(t, err, errorsmsg.ErrTrackNotFound)
+	f.assertOrder(t, playlist.ID, "1", "2", "3")
+}
+
+func testReorderTracks(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 5)
+
+	// Only the listed entries move, between the positions they held
+	order := []string{tracks[4].ID, tracks[1].ID, tracks[3].ID}
+	if err := f.repo.ReorderTracks(f.ctx, playlist.ID, order); err != nil {
+		t.Fatalf("ReorderTracks: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "1", "5", "3", "2", "4")
+}
+
+func testReorderTracksSkipsRemoved(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 4)
+	if err := f.repo.RemoveTrack(f.ctx, playlist.ID, tracks[1].ID); err != nil {
+		t.Fatalf("RemoveTrack: %v", err)
+	}
+
+	order := []string{tracks[3].ID, tracks[1].ID, tracks[2].ID, tracks[0].ID}
+	if err := f.repo.ReorderTracks(f.ctx, playlist.ID, order); err != nil {
+		t.Fatalf("ReorderTracks: %v", err)
+	}
+	f.assertOrder(t, playlist.ID, "4", "3", "1")
+}
+
+func testReorderTracksNotFound(t *testing.T, f *fixture) {
+	err := f.repo.ReorderTracks(f.ctx, uuid.New().String(), []string{uuid.New().String()})
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
+
+func testCurrentTrack(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 3)
+
+	current, err := f.repo.GetCurrentTrack(f.ctx, playlist.ID)
+	if err != nil || current != nil {
+		t.Fatalf("GetCurrentTrack before playing = %+v, %v; want nil, nil", current, err)
+	}
+
+	for _, track := range []*model.Playlist_Track{tracks[0], tracks[2]} {
+		if err := f.repo.SetCurrentTrack(f.ctx, playlist.ID, track.ID); err != nil {
+			t.Fatalf("SetCurrentTrack: %v", err)
+		}
+		current, err := f.repo.GetCurrentTrack(f.ctx, playlist.ID)
+		if err != nil {
+			t.Fatalf("GetCurrentTrack: %v", err)
+		}
+		if current == nil || current.ID != track.ID || !current.IsPlaying || current.StartedAt == nil {
+			t.Fatalf("GetCurrentTrack = %+v, want %s playing", current, track.ID)
+		}
+	}
+
+	all, err := f.repo.GetPlaylistTracks(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	playing := 0
+	for _, track := range all {
+		if track.IsPlaying {
+			playing++
+		}
+	}
+	if playing != 1 {
+		t.Errorf("%d tracks playing, want 1", playing)
+	}
+
+	if err := f.repo.ClearCurrentTrack(f.ctx, playlist.ID); err != nil {
+		t.Fatalf("ClearCurrentTrack: %v", err)
+	}
+	current, err = f.repo.GetCurrentTrack(f.ctx, playlist.ID)
+	if err != nil || current != nil {
+		t.Errorf("GetCurrentTrack after clear = %+v, %v; want nil, nil", current, err)
+	}
+}
+
+func testSetCurrentTrackNotFound(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 1)
+
+	err := f.repo.SetCurrentTrack(f.ctx, uuid.New().String(), tracks[0].ID)
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+
+	if err := f.repo.SetCurrentTrack(f.ctx, playlist.ID, tracks[0].ID); err != nil {
+		t.Fatalf("SetCurrentTrack: %v", err)
+	}
+	err = f.repo.SetCurrentTrack(f.ctx, playlist.ID, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrTrackNotFound)
+
+	err = f.repo.ClearCurrentTrack(f.ctx, uuid.New().String())
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
+
+func testGetAdjacentTrack(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 3)
+
+	tests := []struct {
+		position int
+		forward  bool
+		want     *model.Playlist_Track
+	}{
+		{1, true, tracks[1]},
+		{2, true, tracks[2]},
+		{3, true, nil},
+		{3, false, tracks[1]},
+		{1, false, nil},
+		{0, true, tracks[0]},
+	}
+	for _, tt := range tests {
+		got, err := f.repo.GetAdjacentTrack(f.ctx, playlist.ID, tt.position, tt.forward)
+		if err != nil {
+			t.Fatalf("GetAdjacentTrack(%d, %t): %v", tt.position, tt.forward, err)
+		}
+		switch {
+		case tt.want == nil && got != nil:
+			t.Errorf("GetAdjacentTrack(%d, %t) = %s, want nil", tt.position, tt.forward, got.Title)
+		case tt.want != nil && (got == nil || got.ID != tt.want.ID):
+			t.Errorf("GetAdjacentTrack(%d, %t) = %+v, want %s", tt.position, tt.forward, got, tt.want.Title)
+		}
+	}
+}
+
+func testPlaylistTracksScore(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 2)
+	votes := f.repo.GetVoteRepository()
+
+	for i, value := range []int{1, 1, -1} {
+		err := votes.CastVote(f.ctx, &model.Vote{
+			PlaylistID: playlist.ID,
+			TrackID:    tracks[1].ID,
+			VoterKey:   fmt.Sprint("voter-", i),
+			Value:      value,
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("CastVote: %v", err)
+		}
+	}
+
+	all, err := f.repo.GetPlaylistTracks(f.ctx, playlist.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	if all[0].Score != 0 || all[1].Score != 1 {
+		t.Errorf("scores = %d, %d; want 0, 1", all[0].Score, all[1].Score)
+	}
+}
+
+// collectPlaylists pages through a host's playlists and returns their names.
+func (f *fixture) collectPlaylists(t *testing.T, hostID string, filter model.PlaylistFilter, opts model.ListOptions) []string {
+	t.Helper()
+	var names []string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("ListPlaylistsByHost doesn't stop paging")
+		}
+		page, err := f.repo.ListPlaylistsByHost(f.ctx, hostID, filter, opts)
+		if err != nil {
+			t.Fatalf("ListPlaylistsByHost: %v", err)
+		}
+		if len(page.Items) > opts.Limit {
+			t.Fatalf("got %d items, limit %d", len(page.Items), opts.Limit)
+		}
+		for _, playlist := range page.Items {
+			names = append(names, playlist.Name)
+		}
+		if page.NextCursor == "" {
+			return names
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// collectTracks pages through a playlist's tracks and returns their titles.
+func (f *fixture) collectTracks(t *testing.T, playlistID string, filter model.TrackFilter, opts model.ListOptions) []string {
+	t.Helper()
+	var titles []string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("ListPlaylistTracks doesn't stop paging")
+		}
+		page, err := f.repo.ListPlaylistTracks(f.ctx, playlistID, filter, opts)
+		if err != nil {
+			t.Fatalf("ListPlaylistTracks: %v", err)
+		}
+		if len(page.Items) > opts.Limit {
+			t.Fatalf("got %d items, limit %d", len(page.Items), opts.Limit)
+		}
+		for _, track := range page.Items {
+			titles = append(titles, track.Title)
+		}
+		if page.NextCursor == "" {
+			return titles
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func testListPlaylistsByHost(t *testing.T, f *fixture) {
+	host := f.host(t)
+	for _, name := range []string{"b", "d", "a", "c", "e"} {
+		playlist := &model.Playlist{ID: uuid.New().String(), Name: name, HostID: host.ID, IsModerated: true}
+		if err := f.repo.CreatePlaylist(f.ctx, playlist); err != nil {
+			t.Fatalf("CreatePlaylist: %v", err)
+		}
+	}
+	f.playlist(t, f.host(t).ID)
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"name", "[a b c d e]"},
+		{"-name", "[e d c b a]"},
+	}
+	for _, tt := range tests {
+		got := f.collectPlaylists(t, host.ID, model.PlaylistFilter{}, model.ListOptions{Limit: 2, Sort: tt.sort})
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("sort %s: names = %v, want %s", tt.sort, got, tt.want)
+		}
+	}
+
+	// Sorting by created_at returns every playlist once
+	got := f.collectPlaylists(t, host.ID, model.PlaylistFilter{}, model.ListOptions{Limit: 2, Sort: "-created_at"})
+	if len(got) != 5 {
+		t.Errorf("sort -created_at: names = %v, want 5 playlists", got)
+	}
+}
+
+func testListPlaylistsByHostFilter(t *testing.T, f *fixture) {
+	host := f.host(t)
+	for _, name := range []string{"Friday Night", "Saturday night", "Sunday brunch", "100% night"} {
+		playlist := &model.Playlist{ID: uuid.New().String(), Name: name, HostID: host.ID, IsModerated: true}
+		if err := f.repo.CreatePlaylist(f.ctx, playlist); err != nil {
+			t.Fatalf("CreatePlaylist: %v", err)
+		}
+	}
+	brunch, err := f.repo.ListPlaylistsByHost(f.ctx, host.ID, model.PlaylistFilter{Name: "brunch"}, model.ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListPlaylistsByHost: %v", err)
+	}
+	brunch.Items[0].IsModerated = false
+	if err := f.repo.UpdatePlaylist(f.ctx, brunch.Items[0]); err != nil {
+		t.Fatalf("UpdatePlaylist: %v", err)
+	}
+
+	moderated := true
+	tests := []struct {
+		filter model.PlaylistFilter
+		want   string
+	}{
+		{model.PlaylistFilter{Name: "NIGHT"}, "[100% night Friday Night Saturday night]"},
+		{model.PlaylistFilter{Name: "%"}, "[100% night]"},
+		{model.PlaylistFilter{Name: "_"}, "[]"},
+		{model.PlaylistFilter{IsModerated: &moderated}, "[100% night Friday Night Saturday night]"},
+		{model.PlaylistFilter{IsModerated: &moderated, Name: "sat"}, "[Saturday night]"},
+	}
+	for _, tt := range tests {
+		got := f.collectPlaylists(t, host.ID, tt.filter, model.ListOptions{Limit: 10, Sort: "name"})
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("filter %+v: names = %v, want %s", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func testListPlaylistTracks(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	tracks := f.tracks(t, playlist.ID, 5)
+	if err := f.repo.UpdateTrackPosition(f.ctx, playlist.ID, tracks[4].ID, 1); err != nil {
+		t.Fatalf("UpdateTrackPosition: %v", err)
+	}
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", "[5 1 2 3 4]"},
+		{"position", "[5 1 2 3 4]"},
+		{"-position", "[4 3 2 1 5]"},
+		{"title", "[1 2 3 4 5]"},
+		{"-title", "[5 4 3 2 1]"},
+	}
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 5, 10} {
+			got := f.collectTracks(t, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: limit, Sort: tt.sort})
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("sort %q limit %d: titles = %v, want %s", tt.sort, limit, got, tt.want)
+			}
+		}
+	}
+
+	got := f.collectTracks(t, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: 2, Sort: "added_at"})
+	if len(got) != 5 {
+		t.Errorf("sort added_at: titles = %v, want 5 tracks", got)
+	}
+}
+
+func testListPlaylistTracksFilter(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	for i, artist := range []string{"The Beatles", "Beat Happening", "Queen"} {
+		track := &model.Playlist_Track{
+			ID:         uuid.New().String(),
+			PlaylistID: playlist.ID,
+			TrackID:    uuid.New().String(),
+			Title:      fmt.Sprint("Song ", i+1),
+			Artist:     artist,
+			Position:   i + 1,
+			AddedAt:    time.Now(),
+		}
+		if err := f.repo.AddTrack(f.ctx, track); err != nil {
+			t.Fatalf("AddTrack: %v", err)
+		}
+	}
+
+	tests := []struct {
+		filter model.TrackFilter
+		want   string
+	}{
+		{model.TrackFilter{Artist: "beat"}, "[Song 1 Song 2]"},
+		{model.TrackFilter{Artist: "beat", Title: "2"}, "[Song 2]"},
+		{model.TrackFilter{Title: "song"}, "[Song 1 Song 2 Song 3]"},
+		{model.TrackFilter{Artist: "abba"}, "[]"},
+	}
+	for _, tt := range tests {
+		got := f.collectTracks(t, playlist.ID, tt.filter, model.ListOptions{Limit: 1})
+		if fmt.Sprint(got) != tt.want {
+			t.Errorf("filter %+v: titles = %v, want %s", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func testListInvalidOptions(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	f.tracks(t, playlist.ID, 3)
+
+	_, err := f.repo.ListPlaylistTracks(f.ctx, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: 1, Sort: "score"})
+	assertErr(t, err, errorsmsg.ErrInvalidSort)
+
+	_, err = f.repo.ListPlaylistsByHost(f.ctx, playlist.HostID, model.PlaylistFilter{}, model.ListOptions{Limit: 1, Sort: "position"})
+	assertErr(t, err, errorsmsg.ErrInvalidSort)
+
+	_, err = f.repo.ListPlaylistTracks(f.ctx, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: 1, Cursor: "not a cursor"})
+	assertErr(t, err, errorsmsg.ErrInvalidCursor)
+
+	// A cursor only resumes the sort it was issued for
+	page, err := f.repo.ListPlaylistTracks(f.ctx, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: 1, Sort: "title"})
+	if err != nil {
+		t.Fatalf("ListPlaylistTracks: %v", err)
+	}
+	_, err = f.repo.ListPlaylistTracks(f.ctx, playlist.ID, model.TrackFilter{}, model.ListOptions{Limit: 1, Cursor: page.NextCursor})
+	assertErr(t, err, errorsmsg.ErrInvalidCursor)
+}
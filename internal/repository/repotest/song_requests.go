@@ -0,0 +1,120 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/google/uuid"
+)
+
+// RunSongRequestRepository runs the SongRequestRepository tests.
+func RunSongRequestRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, f *fixture)
+	}{
+		{"CreateSongRequestLimit", testCreateSongRequestLimit},
+		{"CreateSongRequestLimitConcurrent", testCreateSongRequestLimitConcurrent},
+		{"CreateSongRequestPlaylistNotFound", testCreateSongRequestPlaylistNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, &fixture{ctx: context.Background(), repo: newRepo(t)})
+		})
+	}
+}
+
+func newSongRequest(playlistID, clientKey string, createdAt time.Time) *model.SongRequest {
+	return &model.SongRequest{
+		ID:         uuid.New().String(),
+		PlaylistID: playlistID,
+		Title:      "Song",
+		Artist:     "Artist",
+		Duration:   180,
+		Nickname:   "guest",
+		ClientKey:  clientKey,
+		Status:     model.SongRequestPending,
+		CreatedAt:  createdAt,
+	}
+}
+
+func testCreateSongRequestLimit(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	requests := f.repo.GetSongRequestRepository()
+	now := time.Now().Truncate(time.Second)
+	const window = 10 * time.Minute
+
+	// Requests from before the window don't count
+	old := newSongRequest(playlist.ID, "client-a", now.Add(-time.Hour))
+	if err := requests.CreateSongRequest(f.ctx, old, 2, window); err != nil {
+		t.Fatalf("CreateSongRequest: %v", err)
+	}
+	for i, age := range []time.Duration{4 * time.Minute, time.Minute} {
+		if err := requests.CreateSongRequest(f.ctx, newSongRequest(playlist.ID, "client-a", now.Add(-age)), 2, window); err != nil {
+			t.Fatalf("CreateSongRequest %d: %v", i, err)
+		}
+	}
+	err := requests.CreateSongRequest(f.ctx, newSongRequest(playlist.ID, "client-a", now), 2, window)
+	assertErr(t, err, errorsmsg.ErrTooManySongRequests)
+
+	// The client may retry once the oldest request leaves the window
+	var retry *errorsmsg.RetryError
+	if !errors.As(err, &retry) || retry.RetryAfter != 6*time.Minute {
+		t.Errorf("err = %#v, want a RetryError after %v", err, 6*time.Minute)
+	}
+
+	// Other clients have limits of their own
+	if err := requests.CreateSongRequest(f.ctx, newSongRequest(playlist.ID, "client-b", now), 2, window); err != nil {
+		t.Fatalf("CreateSongRequest for another client: %v", err)
+	}
+
+	stored, err := requests.GetSongRequests(f.ctx, playlist.ID, "")
+	if err != nil {
+		t.Fatalf("GetSongRequests: %v", err)
+	}
+	if len(stored) != 4 {
+		t.Errorf("got %d stored requests, want 4", len(stored))
+	}
+}
+
+func testCreateSongRequestLimitConcurrent(t *testing.T, f *fixture) {
+	playlist := f.playlist(t, f.host(t).ID)
+	requests := f.repo.GetSongRequestRepository()
+	now := time.Now()
+
+	const attempts, limit = 10, 3
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = requests.CreateSongRequest(f.ctx, newSongRequest(playlist.ID, "client", now), limit, time.Minute)
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, errorsmsg.ErrTooManySongRequests):
+		default:
+			t.Fatalf("CreateSongRequest: %v", err)
+		}
+	}
+	if created != limit {
+		t.Errorf("created %d requests concurrently, want %d", created, limit)
+	}
+}
+
+func testCreateSongRequestPlaylistNotFound(t *testing.T, f *fixture) {
+	err := f.repo.GetSongRequestRepository().CreateSongRequest(f.ctx, newSongRequest(uuid.New().String(), "client", time.Now()), 3, time.Minute)
+	assertErr(t, err, errorsmsg.ErrPlaylistNotFound)
+}
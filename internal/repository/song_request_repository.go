@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type SongRequestRepository interface {
+	// CreateSongRequest stores request unless its client already made limit
+	// requests to the playlist within window of its CreatedAt. It fails then
+	// with a RetryError wrapping ErrTooManySongRequests, retryable once
+	// enough of those requests leave the window. The check and the insert
+	// are atomic, so concurrent requests can't all pass the limit.
+	CreateSongRequest(ctx context.Context, request *model.SongRequest, limit int, window time.Duration) error
+	GetSongRequest(ctx context.Context, playlistID, id string) (*model.SongRequest, error)
+	// GetSongRequests lists a playlist's requests oldest first. An empty
+	// status returns requests in any status.
+	GetSongRequests(ctx context.Context, playlistID string, status model.SongRequestStatus) ([]*model.SongRequest, error)
+	// ReviewSongRequest moves a pending request to status. It fails with
+	// ErrSongRequestReviewed if the request is no longer pending.
+	ReviewSongRequest(ctx context.Context, request *model.SongRequest) error
+	// ReopenSongRequest puts a reviewed request back in the pending queue.
+	ReopenSongRequest(ctx context.Context, id string) error
+}
+
+type songRequestRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewSongRequestRepository(db *sql.DB) SongRequestRepository {
+	return &songRequestRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *songRequestRepository) CreateSongRequest(ctx context.Context, request *model.SongRequest, limit int, window time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the playlist rather than the client's requests, as the client
+	// may have none to lock yet
+	var id string
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind("SELECT id FROM playlists WHERE id = ?"+r.dialect.forUpdate()),
+		request.PlaylistID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.ErrPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	since := request.CreatedAt.Add(-window)
+	var count int
+	err = tx.QueryRowContext(ctx,
+		r.dialect.rebind(`SELECT COUNT(*)
+		FROM song_requests
+		WHERE playlist_id = ? AND client_key = ? AND created_at >= ?`),
+		request.PlaylistID, request.ClientKey, since).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		// The limit lifts when the request that takes the count below it
+		// leaves the window
+		var expiring time.Time
+		err = tx.QueryRowContext(ctx,
+			r.dialect.rebind(`SELECT created_at
+			FROM song_requests
+			WHERE playlist_id = ? AND client_key = ? AND created_at >= ?
+			ORDER BY created_at
+			LIMIT 1 OFFSET ?`),
+			request.PlaylistID, request.ClientKey, since, count-limit).Scan(&expiring)
+		if err != nil {
+			return err
+		}
+		return songRequestLimitError(request, expiring, window)
+	}
+
+	query := `
+		INSERT INTO song_requests (id, playlist_id, title, artist, duration, nickname, client_key, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, r.dialect.rebind(query),
+		request.ID,
+		request.PlaylistID,
+		request.Title,
+		request.Artist,
+		request.Duration,
+		request.Nickname,
+		request.ClientKey,
+		request.Status,
+		request.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// songRequestLimitError reports a request over the limit, retryable once
+// the request created at expiring leaves the window.
+func songRequestLimitError(request *model.SongRequest, expiring time.Time, window time.Duration) error {
+	return &errors.RetryError{
+		Err:        errors.ErrTooManySongRequests,
+		RetryAfter: expiring.Add(window).Sub(request.CreatedAt),
+	}
+}
+
+func (r *songRequestRepository) GetSongRequest(ctx context.Context, playlistID, id string) (*model.SongRequest, error) {
+	query := `
+		SELECT id, playlist_id, title, artist, duration, nickname, client_key, status, track_id, created_at, reviewed_at
+		FROM song_requests
+		WHERE playlist_id = ? AND id = ?
+	`
+	request, err := scanSongRequest(r.db.QueryRowContext(ctx, r.dialect.rebind(query), playlistID, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrSongRequestNotFound
+	}
+	return request, err
+}
+
+func (r *songRequestRepository) GetSongRequests(ctx context.Context, playlistID string, status model.SongRequestStatus) ([]*model.SongRequest, error) {
+	query := `
+		SELECT id, playlist_id, title, artist, duration, nickname, client_key, status, track_id, created_at, reviewed_at
+		FROM song_requests
+		WHERE playlist_id = ?
+	`
+	args := []any{playlistID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at"
+	rows, err := r.db.QueryContext(ctx, r.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*model.SongRequest
+	for rows.Next() {
+		request, err := scanSongRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+func (r *songRequestRepository) ReviewSongRequest(ctx context.Context, request *model.SongRequest) error {
+	query := `
+		UPDATE song_requests
+		SET status = ?, track_id = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		request.Status,
+		nullString(request.TrackID),
+		request.ReviewedAt,
+		request.ID,
+		model.SongRequestPending,
+	)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.ErrSongRequestReviewed
+	}
+	return nil
+}
+
+func (r *songRequestRepository) ReopenSongRequest(ctx context.Context, id string) error {
+	query := `
+		UPDATE song_requests
+		SET status = ?, track_id = NULL, reviewed_at = NULL
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), model.SongRequestPending, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSongRequest(row rowScanner) (*model.SongRequest, error) {
+	request := &model.SongRequest{}
+	var trackID sql.NullString
+	var reviewedAt sql.NullTime
+	err := row.Scan(
+		&request.ID,
+		&request.PlaylistID,
+		&request.Title,
+		&request.Artist,
+		&request.Duration,
+		&request.Nickname,
+		&request.ClientKey,
+		&request.Status,
+		&trackID,
+		&request.CreatedAt,
+		&reviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.TrackID = trackID.String
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+	return request, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/config"
+	"github.com/dmarquinah/publist_backend/internal/migrations"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/dmarquinah/publist_backend/internal/repository/repotest"
+)
+
+func TestSQLiteRepository(t *testing.T) {
+	db, err := config.NewDB(&config.DBConfig{
+		Driver:      config.DriverSQLite,
+		Path:        filepath.Join(t.TempDir(), "publist.db"),
+		BusyTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db, config.DriverSQLite)
+	if err != nil {
+		t.Fatalf("loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("applying migrations: %v", err)
+	}
+
+	repo := repository.NewSQLiteRepository(db)
+	repotest.Run(t, func(t *testing.T) repository.Repository {
+		return repo
+	})
+}
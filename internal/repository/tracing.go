@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/tracing"
+)
+
+// tracedPlaylistRepository adds a client span around every call to next,
+// naming the SQL operation it performs and the playlist it touches.
+type tracedPlaylistRepository struct {
+	next   PlaylistRepository
+	system string
+}
+
+func (r *tracedPlaylistRepository) start(ctx context.Context, method, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemKey.String(r.system), semconv.DBOperationName(operation))
+	return tracing.Tracer().Start(ctx, "PlaylistRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (r *tracedPlaylistRepository) CreatePlaylist(ctx context.Context, playlist *model.Playlist) (err error) {
+	ctx, span := r.start(ctx, "CreatePlaylist", "INSERT", tracing.PlaylistIDKey.String(playlist.ID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.CreatePlaylist(ctx, playlist)
+}
+
+func (r *tracedPlaylistRepository) GetPlaylist(ctx context.Context, id string) (_ *model.Playlist, err error) {
+	ctx, span := r.start(ctx, "GetPlaylist", "SELECT", tracing.PlaylistIDKey.String(id))
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetPlaylist(ctx, id)
+}
+
+func (r *tracedPlaylistRepository) UpdatePlaylist(ctx context.Context, playlist *model.Playlist) (err error) {
+	ctx, span := r.start(ctx, "UpdatePlaylist", "UPDATE", tracing.PlaylistIDKey.String(playlist.ID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.UpdatePlaylist(ctx, playlist)
+}
+
+func (r *tracedPlaylistRepository) DeletePlaylist(ctx context.Context, id string) (err error) {
+	ctx, span := r.start(ctx, "DeletePlaylist", "DELETE", tracing.PlaylistIDKey.String(id))
+	defer func() { tracing.End(span, err) }()
+	return r.next.DeletePlaylist(ctx, id)
+}
+
+func (r *tracedPlaylistRepository) GetPlaylistsByHost(ctx context.Context, hostID string) (_ []*model.Playlist, err error) {
+	ctx, span := r.start(ctx, "GetPlaylistsByHost", "SELECT")
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetPlaylistsByHost(ctx, hostID)
+}
+
+func (r *tracedPlaylistRepository) ListPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (_ *model.Page[*model.Playlist], err error) {
+	ctx, span := r.start(ctx, "ListPlaylistsByHost", "SELECT")
+	defer func() { tracing.End(span, err) }()
+	return r.next.ListPlaylistsByHost(ctx, hostID, filter, opts)
+}
+
+func (r *tracedPlaylistRepository) GetTrack(ctx context.Context, id string) (_ *model.Track, err error) {
+	ctx, span := r.start(ctx, "GetTrack", "SELECT", tracing.TrackIDKey.String(id))
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetTrack(ctx, id)
+}
+
+func (r *tracedPlaylistRepository) AddTrack(ctx context.Context, track *model.Playlist_Track) (err error) {
+	ctx, span := r.start(ctx, "AddTrack", "INSERT", tracing.PlaylistIDKey.String(track.PlaylistID), tracing.TrackIDKey.String(track.ID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.AddTrack(ctx, track)
+}
+
+func (r *tracedPlaylistRepository) RemoveTrack(ctx context.Context, playlistID, trackID string) (err error) {
+	ctx, span := r.start(ctx, "RemoveTrack", "DELETE", tracing.PlaylistIDKey.String(playlistID), tracing.TrackIDKey.String(trackID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.RemoveTrack(ctx, playlistID, trackID)
+}
+
+func (r *tracedPlaylistRepository) UpdateTrackPosition(ctx context.Context, playlistID, trackID string, newPosition int) (err error) {
+	ctx, span := r.start(ctx, "UpdateTrackPosition", "UPDATE", tracing.PlaylistIDKey.String(playlistID), tracing.TrackIDKey.String(trackID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.UpdateTrackPosition(ctx, playlistID, trackID, newPosition)
+}
+
+func (r *tracedPlaylistRepository) ReorderTracks(ctx context.Context, playlistID string, trackIDs []string) (err error) {
+	ctx, span := r.start(ctx, "ReorderTracks", "UPDATE", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.ReorderTracks(ctx, playlistID, trackIDs)
+}
+
+func (r *tracedPlaylistRepository) GetCurrentTrack(ctx context.Context, playlistID string) (_ *model.Playlist_Track, err error) {
+	ctx, span := r.start(ctx, "GetCurrentTrack", "SELECT", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetCurrentTrack(ctx, playlistID)
+}
+
+func (r *tracedPlaylistRepository) GetPlaylistTracks(ctx context.Context, playlistID string) (_ []*model.Playlist_Track, err error) {
+	ctx, span := r.start(ctx, "GetPlaylistTracks", "SELECT", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetPlaylistTracks(ctx, playlistID)
+}
+
+func (r *tracedPlaylistRepository) ListPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (_ *model.Page[*model.Playlist_Track], err error) {
+	ctx, span := r.start(ctx, "ListPlaylistTracks", "SELECT", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.ListPlaylistTracks(ctx, playlistID, filter, opts)
+}
+
+func (r *tracedPlaylistRepository) SetCurrentTrack(ctx context.Context, playlistID, trackID string) (err error) {
+	ctx, span := r.start(ctx, "SetCurrentTrack", "UPDATE", tracing.PlaylistIDKey.String(playlistID), tracing.TrackIDKey.String(trackID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.SetCurrentTrack(ctx, playlistID, trackID)
+}
+
+func (r *tracedPlaylistRepository) ClearCurrentTrack(ctx context.Context, playlistID string) (err error) {
+	ctx, span := r.start(ctx, "ClearCurrentTrack", "UPDATE", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.ClearCurrentTrack(ctx, playlistID)
+}
+
+func (r *tracedPlaylistRepository) GetAdjacentTrack(ctx context.Context, playlistID string, position int, forward bool) (_ *model.Playlist_Track, err error) {
+	ctx, span := r.start(ctx, "GetAdjacentTrack", "SELECT", tracing.PlaylistIDKey.String(playlistID))
+	defer func() { tracing.End(span, err) }()
+	return r.next.GetAdjacentTrack(ctx, playlistID, position, forward)
+}
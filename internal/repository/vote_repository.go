@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dmarquinah/publist_backend/internal/model"
+)
+
+type VoteRepository interface {
+	// CastVote records a vote, replacing the voter's previous vote on the
+	// same track.
+	CastVote(ctx context.Context, vote *model.Vote) error
+	RemoveVote(ctx context.Context, playlistID, trackID, voterKey string) error
+	GetTrackScore(ctx context.Context, playlistID, trackID string) (int, error)
+}
+
+type voteRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+func NewVoteRepository(db *sql.DB) VoteRepository {
+	return &voteRepository{db: db, dialect: mysqlDialect}
+}
+
+func (r *voteRepository) CastVote(ctx context.Context, vote *model.Vote) error {
+	query := `
+		INSERT INTO track_votes (playlist_id, track_id, voter_key, value, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	` + r.dialect.upsert([]string{"playlist_id", "track_id", "voter_key"}, "value", "created_at")
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		vote.PlaylistID,
+		vote.TrackID,
+		vote.VoterKey,
+		vote.Value,
+		vote.CreatedAt,
+	)
+	return err
+}
+
+func (r *voteRepository) RemoveVote(ctx context.Context, playlistID, trackID, voterKey string) error {
+	query := `DELETE FROM track_votes WHERE playlist_id = ? AND track_id = ? AND voter_key = ?`
+	_, err := r.db.ExecContext(ctx, r.dialect.rebind(query), playlistID, trackID, voterKey)
+	return err
+}
+
+func (r *voteRepository) GetTrackScore(ctx context.Context, playlistID, trackID string) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(value), 0)
+		FROM track_votes
+		WHERE playlist_id = ? AND track_id = ?
+	`
+	var score int
+	err := r.db.QueryRowContext(ctx, r.dialect.rebind(query), playlistID, trackID).Scan(&score)
+	return score, err
+}
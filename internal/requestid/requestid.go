@@ -0,0 +1,22 @@
+// Package requestid carries the ID of the request being served, so errors
+// and logs can be correlated with what the client saw.
+package requestid
+
+import "context"
+
+// Header is the header a request ID is read from and echoed back in.
+const Header = "X-Request-ID"
+
+// idKey is the context key under which the request ID is stored.
+type idKey struct{}
+
+// NewContext returns a copy of ctx carrying the given request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+)
+
+// dummyHash is compared against when the email is unknown so that failed
+// logins take the same time whether or not the host exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("publist-dummy-password"), bcrypt.DefaultCost)
+
+type AuthService interface {
+	Register(ctx context.Context, host *model.Host, password string) (*model.TokenPair, error)
+	Login(ctx context.Context, email, password string) (*model.Host, *model.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type authService struct {
+	hosts      repository.HostRepository
+	tokens     repository.RefreshTokenRepository
+	jwtManager *auth.JWTManager
+	refreshTTL time.Duration
+}
+
+func NewAuthService(hosts repository.HostRepository, tokens repository.RefreshTokenRepository, jwtManager *auth.JWTManager, refreshTTL time.Duration) AuthService {
+	return &authService{
+		hosts:      hosts,
+		tokens:     tokens,
+		jwtManager: jwtManager,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (s *authService) Register(ctx context.Context, host *model.Host, password string) (*model.TokenPair, error) {
+	host.Name = strings.TrimSpace(host.Name)
+	host.Email = normalizeEmail(host.Email)
+	if host.Role == "" {
+		host.Role = rbac.RoleHost
+	}
+
+	if host.Name == "" || utf8.RuneCountInString(host.Name) > 255 {
+		return nil, errorsmsg.ErrInvalidHostName
+	}
+	if _, err := mail.ParseAddress(host.Email); err != nil {
+		return nil, errorsmsg.ErrInvalidEmail
+	}
+	// Admins are appointed, never self-registered
+	if !host.Role.Valid() || host.Role == rbac.RoleAdmin {
+		return nil, errorsmsg.ErrInvalidRole
+	}
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return nil, errorsmsg.ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hashing password: %w", err)
+	}
+
+	host.ID = uuid.New().String()
+	host.PasswordHash = string(hash)
+	host.CreatedAt = time.Now()
+	host.IsActive = true
+
+	if err := s.hosts.CreateHost(ctx, host); err != nil {
+		if errors.Is(err, errorsmsg.ErrEmailTaken) {
+			return nil, errorsmsg.ErrEmailTaken
+		}
+		return nil, fmt.Errorf("creating host: %w", err)
+	}
+
+	return s.startSession(ctx, host)
+}
+
+func (s *authService) Login(ctx context.Context, email, password string) (*model.Host, *model.TokenPair, error) {
+	host, err := s.hosts.GetHostByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrHostNotFound) {
+			bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			return nil, nil, errorsmsg.ErrInvalidCredentials
+		}
+		return nil, nil, fmt.Errorf("fetching host: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(host.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, errorsmsg.ErrInvalidCredentials
+	}
+
+	// Only reveal the account state once the password has been verified
+	if !host.IsActive {
+		return nil, nil, errorsmsg.ErrHostInactive
+	}
+
+	tokens, err := s.startSession(ctx, host)
+	if err != nil {
+		return nil, nil, err
+	}
+	return host, tokens, nil
+}
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	current, err := s.tokens.GetRefreshTokenByHash(ctx, auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrInvalidRefreshToken) {
+			return nil, errorsmsg.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("fetching refresh token: %w", err)
+	}
+
+	if current.RevokedAt != nil {
+		// A rotated token being presented again means it leaked: revoke
+		// every token descended from the same login.
+		if current.ReplacedBy != "" {
+			if err := s.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return nil, fmt.Errorf("revoking token family: %w", err)
+			}
+			return nil, errorsmsg.ErrRefreshTokenReused
+		}
+		return nil, errorsmsg.ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errorsmsg.ErrRefreshTokenExpired
+	}
+
+	host, err := s.hosts.GetHost(ctx, current.HostID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrHostNotFound) {
+			return nil, errorsmsg.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("fetching host: %w", err)
+	}
+	if !host.IsActive {
+		if err := s.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+			return nil, fmt.Errorf("revoking token family: %w", err)
+		}
+		return nil, errorsmsg.ErrHostInactive
+	}
+
+	raw, next, err := s.newRefreshToken(host.ID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.RotateRefreshToken(ctx, current.ID, next); err != nil {
+		if errors.Is(err, errorsmsg.ErrRefreshTokenReused) {
+			if err := s.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return nil, fmt.Errorf("revoking token family: %w", err)
+			}
+			return nil, errorsmsg.ErrRefreshTokenReused
+		}
+		return nil, fmt.Errorf("rotating refresh token: %w", err)
+	}
+
+	return s.tokenPair(host, raw)
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	current, err := s.tokens.GetRefreshTokenByHash(ctx, auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrInvalidRefreshToken) {
+			return errorsmsg.ErrInvalidRefreshToken
+		}
+		return fmt.Errorf("fetching refresh token: %w", err)
+	}
+
+	if err := s.tokens.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+		return fmt.Errorf("revoking token family: %w", err)
+	}
+	return nil
+}
+
+// startSession issues the first refresh token of a new family.
+func (s *authService) startSession(ctx context.Context, host *model.Host) (*model.TokenPair, error) {
+	raw, token, err := s.newRefreshToken(host.ID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.CreateRefreshToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("storing refresh token: %w", err)
+	}
+	return s.tokenPair(host, raw)
+}
+
+func (s *authService) newRefreshToken(hostID, familyID string) (string, *model.RefreshToken, error) {
+	raw, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("generating refresh token: %w", err)
+	}
+	now := time.Now()
+	return raw, &model.RefreshToken{
+		ID:        uuid.New().String(),
+		HostID:    hostID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}, nil
+}
+
+func (s *authService) tokenPair(host *model.Host, refreshToken string) (*model.TokenPair, error) {
+	accessToken, err := s.jwtManager.GenerateToken(host.ID, host.Role)
+	if err != nil {
+		return nil, fmt.Errorf("generating token: %w", err)
+	}
+	return &model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.jwtManager.AccessTTL().Seconds()),
+	}, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+)
+
+// authorizer decides what a principal may do to a playlist, from its role
+// and from its membership of the playlist.
+type authorizer struct {
+	playlists repository.PlaylistRepository
+	members   repository.MemberRepository
+}
+
+// authorize fetches the playlist and checks that p holds perm on it.
+func (a *authorizer) authorize(ctx context.Context, playlistID string, p rbac.Principal, perm rbac.Permission) (*model.Playlist, error) {
+	playlist, err := a.playlists.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return nil, errorsmsg.ErrPlaylistNotFound
+		}
+		return nil, fmt.Errorf("fetching playlist: %w", err)
+	}
+
+	allowed, err := a.can(ctx, playlist, p, perm)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errorsmsg.ErrForbidden
+	}
+	return playlist, nil
+}
+
+func (a *authorizer) can(ctx context.Context, playlist *model.Playlist, p rbac.Principal, perm rbac.Permission) (bool, error) {
+	if p.Role.Can(perm) {
+		return true, nil
+	}
+	role, err := a.members.GetPlaylistMemberRole(ctx, playlist.ID, p.UserID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrMemberNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("fetching playlist member: %w", err)
+	}
+	// Editors can only edit while their account is staff or above
+	if role == rbac.MemberEditor && !p.Role.AtLeast(rbac.RoleStaff) {
+		role = rbac.MemberViewer
+	}
+	return role.Can(perm), nil
+}
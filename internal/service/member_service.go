@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+)
+
+// MemberService lets playlist owners share their playlists with other
+// accounts.
+type MemberService interface {
+	// AddMember gives the account registered with email a role in the
+	// playlist. Only staff accounts and above can be editors.
+	AddMember(ctx context.Context, playlistID, email string, role rbac.MemberRole, actor rbac.Principal) (*model.Member, error)
+	// RemoveMember removes anyone but the owner.
+	RemoveMember(ctx context.Context, playlistID, hostID string, actor rbac.Principal) error
+	GetMembers(ctx context.Context, playlistID string, actor rbac.Principal) ([]*model.Member, error)
+}
+
+type memberService struct {
+	members repository.MemberRepository
+	hosts   repository.HostRepository
+	auth    *authorizer
+}
+
+func NewMemberService(members repository.MemberRepository, hosts repository.HostRepository, playlists repository.PlaylistRepository) MemberService {
+	return &memberService{
+		members: members,
+		hosts:   hosts,
+		auth:    &authorizer{playlists: playlists, members: members},
+	}
+}
+
+func (s *memberService) AddMember(ctx context.Context, playlistID, email string, role rbac.MemberRole, actor rbac.Principal) (*model.Member, error) {
+	// A playlist has a single owner, the host who created it
+	if role != rbac.MemberEditor && role != rbac.MemberViewer {
+		return nil, errorsmsg.ErrInvalidMemberRole
+	}
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistManage); err != nil {
+		return nil, err
+	}
+
+	host, err := s.hosts.GetHostByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrHostNotFound) {
+			return nil, errorsmsg.ErrHostNotFound
+		}
+		return nil, fmt.Errorf("fetching host: %w", err)
+	}
+	if !host.IsActive || (role == rbac.MemberEditor && !host.Role.AtLeast(rbac.RoleStaff)) {
+		return nil, errorsmsg.ErrCannotBeMember
+	}
+
+	member := &model.Member{
+		PlaylistID: playlistID,
+		HostID:     host.ID,
+		Name:       host.Name,
+		Email:      host.Email,
+		Role:       role,
+		AddedAt:    time.Now(),
+	}
+	if err := s.members.AddPlaylistMember(ctx, member); err != nil {
+		if errors.Is(err, errorsmsg.ErrAlreadyMember) {
+			return nil, errorsmsg.ErrAlreadyMember
+		}
+		return nil, fmt.Errorf("adding playlist member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *memberService) RemoveMember(ctx context.Context, playlistID, hostID string, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistManage); err != nil {
+		return err
+	}
+
+	role, err := s.members.GetPlaylistMemberRole(ctx, playlistID, hostID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrMemberNotFound) {
+			return errorsmsg.ErrMemberNotFound
+		}
+		return fmt.Errorf("fetching playlist member: %w", err)
+	}
+	if role == rbac.MemberOwner {
+		return errorsmsg.ErrCannotRemoveOwner
+	}
+
+	if err := s.members.RemovePlaylistMember(ctx, playlistID, hostID); err != nil {
+		if errors.Is(err, errorsmsg.ErrMemberNotFound) {
+			return errorsmsg.ErrMemberNotFound
+		}
+		return fmt.Errorf("removing playlist member: %w", err)
+	}
+	return nil
+}
+
+func (s *memberService) GetMembers(ctx context.Context, playlistID string, actor rbac.Principal) ([]*model.Member, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistRead); err != nil {
+		return nil, err
+	}
+
+	members, err := s.members.GetPlaylistMembers(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching playlist members: %w", err)
+	}
+	return members, nil
+}
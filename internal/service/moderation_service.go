@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+const maxPatternLength = 255
+
+// ModerationService manages the blocklists moderated playlists are screened
+// against, and the review queue of tracks they flagged.
+type ModerationService interface {
+	// CreateBlockRule adds a rule to the playlist's blocklist, or to the
+	// global one if the rule has no PlaylistID.
+	CreateBlockRule(ctx context.Context, rule *model.BlockRule, actor rbac.Principal) error
+	DeleteBlockRule(ctx context.Context, playlistID, ruleID string, actor rbac.Principal) error
+	GetBlockRules(ctx context.Context, playlistID string, actor rbac.Principal) ([]*model.BlockRule, error)
+
+	GetModerationFlags(ctx context.Context, filter model.FlagFilter, opts model.ListOptions, actor rbac.Principal) (*model.Page[*model.ModerationFlag], error)
+	// ApproveModerationFlag keeps a flagged track in its playlist.
+	ApproveModerationFlag(ctx context.Context, flagID string, actor rbac.Principal) (*model.ModerationFlag, error)
+	// RemoveFlaggedTrack takes a flagged track out of its playlist.
+	RemoveFlaggedTrack(ctx context.Context, flagID string, actor rbac.Principal) (*model.ModerationFlag, error)
+
+	GetAuditEntries(ctx context.Context, filter model.AuditFilter, opts model.ListOptions, actor rbac.Principal) (*model.Page[*model.AuditEntry], error)
+}
+
+type moderationService struct {
+	repo      repository.ModerationRepository
+	auth      *authorizer
+	moderator *moderator
+	tracks    PlaylistService
+}
+
+func NewModerationService(repo repository.ModerationRepository, playlists repository.PlaylistRepository, members repository.MemberRepository, tracks PlaylistService) ModerationService {
+	return &moderationService{
+		repo:      repo,
+		auth:      &authorizer{playlists: playlists, members: members},
+		moderator: &moderator{repo: repo},
+		tracks:    tracks,
+	}
+}
+
+func (s *moderationService) CreateBlockRule(ctx context.Context, rule *model.BlockRule, actor rbac.Principal) error {
+	if !validBlockRule(rule) {
+		return errorsmsg.ErrInvalidBlockRule
+	}
+	if err := s.authorizeBlocklist(ctx, rule.PlaylistID, actor, rbac.PermPlaylistModerate); err != nil {
+		return err
+	}
+
+	rule.ID = uuid.New().String()
+	rule.CreatedBy = actor.UserID
+	rule.CreatedAt = time.Now()
+
+	if err := s.repo.CreateBlockRule(ctx, rule); err != nil {
+		return fmt.Errorf("creating block rule: %w", err)
+	}
+	s.moderator.audit(ctx, actor.UserID, model.AuditRuleCreated, rule.PlaylistID, rule.ID, describeRule(rule))
+	return nil
+}
+
+func (s *moderationService) DeleteBlockRule(ctx context.Context, playlistID, ruleID string, actor rbac.Principal) error {
+	if err := s.authorizeBlocklist(ctx, playlistID, actor, rbac.PermPlaylistModerate); err != nil {
+		return err
+	}
+
+	rule, err := s.repo.GetBlockRule(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrBlockRuleNotFound) {
+			return errorsmsg.ErrBlockRuleNotFound
+		}
+		return fmt.Errorf("fetching block rule: %w", err)
+	}
+	// Rules can only be deleted through the blocklist they belong to
+	if rule.PlaylistID != playlistID {
+		return errorsmsg.ErrBlockRuleNotFound
+	}
+
+	if err := s.repo.DeleteBlockRule(ctx, ruleID); err != nil {
+		if errors.Is(err, errorsmsg.ErrBlockRuleNotFound) {
+			return errorsmsg.ErrBlockRuleNotFound
+		}
+		return fmt.Errorf("deleting block rule: %w", err)
+	}
+	s.moderator.audit(ctx, actor.UserID, model.AuditRuleDeleted, rule.PlaylistID, rule.ID, describeRule(rule))
+	return nil
+}
+
+func (s *moderationService) GetBlockRules(ctx context.Context, playlistID string, actor rbac.Principal) ([]*model.BlockRule, error) {
+	if err := s.authorizeBlocklist(ctx, playlistID, actor, rbac.PermPlaylistRead); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repo.GetBlockRules(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching block rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *moderationService) GetModerationFlags(ctx context.Context, filter model.FlagFilter, opts model.ListOptions, actor rbac.Principal) (*model.Page[*model.ModerationFlag], error) {
+	if !actor.Role.Can(rbac.PermModerationManage) {
+		return nil, errorsmsg.ErrForbidden
+	}
+	if err := normalizeListOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListModerationFlags(ctx, filter, opts)
+}
+
+func (s *moderationService) ApproveModerationFlag(ctx context.Context, flagID string, actor rbac.Principal) (*model.ModerationFlag, error) {
+	flag, err := s.pendingFlag(ctx, flagID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(ctx, flag, model.FlagApproved, actor); err != nil {
+		return nil, err
+	}
+	s.moderator.audit(ctx, actor.UserID, model.AuditFlagApproved, flag.PlaylistID, flag.ID, describeTrack(flag.Title, flag.Artist))
+	return flag, nil
+}
+
+func (s *moderationService) RemoveFlaggedTrack(ctx context.Context, flagID string, actor rbac.Principal) (*model.ModerationFlag, error) {
+	flag, err := s.pendingFlag(ctx, flagID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	// The track may already be gone, removed by a host in the meantime
+	if err := s.tracks.RemoveTrack(ctx, flag.PlaylistID, flag.EntryID, actor); err != nil && !errors.Is(err, errorsmsg.ErrTrackNotFound) {
+		return nil, err
+	}
+
+	if err := s.review(ctx, flag, model.FlagRemoved, actor); err != nil {
+		return nil, err
+	}
+	s.moderator.audit(ctx, actor.UserID, model.AuditFlagRemoved, flag.PlaylistID, flag.ID, describeTrack(flag.Title, flag.Artist))
+	return flag, nil
+}
+
+func (s *moderationService) GetAuditEntries(ctx context.Context, filter model.AuditFilter, opts model.ListOptions, actor rbac.Principal) (*model.Page[*model.AuditEntry], error) {
+	if !actor.Role.Can(rbac.PermModerationManage) {
+		return nil, errorsmsg.ErrForbidden
+	}
+	if err := normalizeListOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAuditEntries(ctx, filter, opts)
+}
+
+// authorizeBlocklist checks that actor holds perm on the playlist, or may
+// manage moderation if playlistID is empty and the blocklist is global.
+func (s *moderationService) authorizeBlocklist(ctx context.Context, playlistID string, actor rbac.Principal, perm rbac.Permission) error {
+	if playlistID == "" {
+		if !actor.Role.Can(rbac.PermModerationManage) {
+			return errorsmsg.ErrForbidden
+		}
+		return nil
+	}
+	_, err := s.auth.authorize(ctx, playlistID, actor, perm)
+	return err
+}
+
+func (s *moderationService) pendingFlag(ctx context.Context, flagID string, actor rbac.Principal) (*model.ModerationFlag, error) {
+	if !actor.Role.Can(rbac.PermModerationManage) {
+		return nil, errorsmsg.ErrForbidden
+	}
+
+	flag, err := s.repo.GetModerationFlag(ctx, flagID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrModerationFlagNotFound) {
+			return nil, errorsmsg.ErrModerationFlagNotFound
+		}
+		return nil, fmt.Errorf("fetching moderation flag: %w", err)
+	}
+	if flag.Status != model.FlagPending {
+		return nil, errorsmsg.ErrModerationFlagReviewed
+	}
+	return flag, nil
+}
+
+func (s *moderationService) review(ctx context.Context, flag *model.ModerationFlag, status model.FlagStatus, actor rbac.Principal) error {
+	now := time.Now()
+	flag.Status = status
+	flag.ReviewedBy = actor.UserID
+	flag.ReviewedAt = &now
+
+	if err := s.repo.ReviewModerationFlag(ctx, flag); err != nil {
+		if errors.Is(err, errorsmsg.ErrModerationFlagReviewed) {
+			return errorsmsg.ErrModerationFlagReviewed
+		}
+		return fmt.Errorf("reviewing moderation flag: %w", err)
+	}
+	return nil
+}
+
+func validBlockRule(rule *model.BlockRule) bool {
+	switch rule.Kind {
+	case model.BlockArtist, model.BlockTitle, model.BlockWord:
+	default:
+		return false
+	}
+	switch rule.Action {
+	case model.BlockReject, model.BlockFlag:
+	default:
+		return false
+	}
+	return hasWords(rule.Pattern) && utf8.RuneCountInString(rule.Pattern) <= maxPatternLength
+}
+
+func describeRule(rule *model.BlockRule) string {
+	return fmt.Sprintf("%s %s %q", rule.Action, rule.Kind, rule.Pattern)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dmarquinah/publist_backend/internal/logging"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// moderator screens the tracks going into moderated playlists against the
+// global blocklist and the playlist's own, and records what it decides.
+type moderator struct {
+	repo repository.ModerationRepository
+}
+
+// screen returns the rule a track matches, or nil if it may go in
+// unremarked. A rejecting rule wins over a flagging one.
+func (m *moderator) screen(ctx context.Context, playlist *model.Playlist, title, artist string) (*model.BlockRule, error) {
+	if !playlist.IsModerated {
+		return nil, nil
+	}
+
+	global, err := m.repo.GetBlockRules(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching global block rules: %w", err)
+	}
+	own, err := m.repo.GetBlockRules(ctx, playlist.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching playlist block rules: %w", err)
+	}
+
+	var flag *model.BlockRule
+	for _, rule := range append(global, own...) {
+		if !matchesRule(rule, title, artist) {
+			continue
+		}
+		if rule.Action == model.BlockReject {
+			return rule, nil
+		}
+		if flag == nil {
+			flag = rule
+		}
+	}
+	return flag, nil
+}
+
+// flag queues a track let in by a flagging rule for review. The track is
+// already in the playlist, so failures are logged rather than returned.
+func (m *moderator) flag(ctx context.Context, track *model.Playlist_Track, rule *model.BlockRule, actorID string) {
+	flag := &model.ModerationFlag{
+		ID:         uuid.New().String(),
+		PlaylistID: track.PlaylistID,
+		EntryID:    track.ID,
+		Title:      track.Title,
+		Artist:     track.Artist,
+		RuleID:     rule.ID,
+		Kind:       rule.Kind,
+		Pattern:    rule.Pattern,
+		Status:     model.FlagPending,
+		CreatedAt:  time.Now(),
+	}
+	if err := m.repo.CreateModerationFlag(ctx, flag); err != nil {
+		logging.FromContext(ctx).Error("flagging track",
+			slog.String("playlist_id", track.PlaylistID),
+			slog.String("track_id", track.ID),
+			slog.Any("err", err),
+		)
+		return
+	}
+	m.audit(ctx, actorID, model.AuditTrackFlagged, track.PlaylistID, flag.ID, describeTrack(track.Title, track.Artist))
+}
+
+// audit records a moderation decision. The decision stands even if it
+// can't be recorded, so failures are logged rather than returned.
+func (m *moderator) audit(ctx context.Context, actorID string, action model.AuditAction, playlistID, targetID, detail string) {
+	entry := &model.AuditEntry{
+		ID:         uuid.New().String(),
+		ActorID:    actorID,
+		Action:     action,
+		PlaylistID: playlistID,
+		TargetID:   targetID,
+		Detail:     detail,
+		CreatedAt:  time.Now(),
+	}
+	if err := m.repo.AddAuditEntry(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("recording moderation audit",
+			slog.String("action", string(action)),
+			slog.String("target_id", targetID),
+			slog.Any("err", err),
+		)
+	}
+}
+
+func describeTrack(title, artist string) string {
+	if artist == "" {
+		return fmt.Sprintf("%q", title)
+	}
+	return fmt.Sprintf("%q by %q", title, artist)
+}
+
+// matchesRule compares whole words, ignoring case and punctuation, so
+// "AC/DC" matches "ac dc" and the word "hell" doesn't match "hello".
+func matchesRule(rule *model.BlockRule, title, artist string) bool {
+	pattern := words(rule.Pattern)
+	switch rule.Kind {
+	case model.BlockArtist:
+		return words(artist) == pattern
+	case model.BlockTitle:
+		return words(title) == pattern
+	case model.BlockWord:
+		return strings.Contains(words(title), pattern) || strings.Contains(words(artist), pattern)
+	}
+	return false
+}
+
+// words lowercases s and reduces it to its words separated by single
+// spaces, with a space on either side so substrings match whole words.
+func words(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return " " + strings.Join(fields, " ") + " "
+}
+
+// hasWords reports whether s has anything a rule could match on.
+func hasWords(s string) bool {
+	return strings.TrimSpace(words(s)) != ""
+}
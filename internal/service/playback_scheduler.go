@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/logging"
+)
+
+// playbackScheduler fires a callback once the track playing in a playlist
+// has run for its full duration. Timers live in memory only, so playlists
+// resume auto-advancing after a restart on the next playback change.
+type playbackScheduler struct {
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	advance func(ctx context.Context, playlistID, trackID string)
+}
+
+func newPlaybackScheduler(advance func(ctx context.Context, playlistID, trackID string)) *playbackScheduler {
+	return &playbackScheduler{
+		timers:  make(map[string]*time.Timer),
+		advance: advance,
+	}
+}
+
+// Schedule replaces any pending timer of the playlist with one that fires
+// for trackID after the given delay. The callback's context outlives ctx but
+// keeps its logger, tagged with the playlist and track.
+func (s *playbackScheduler) Schedule(ctx context.Context, playlistID, trackID string, after time.Duration) {
+	logger := logging.FromContext(ctx).With(
+		slog.String("playlist_id", playlistID),
+		slog.String("track_id", trackID),
+	)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.timers[playlistID]; ok {
+		t.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(after, func() {
+		s.mu.Lock()
+		current := s.timers[playlistID] == timer
+		if current {
+			delete(s.timers, playlistID)
+		}
+		s.mu.Unlock()
+
+		if current {
+			s.advance(logging.NewContext(context.Background(), logger), playlistID, trackID)
+		}
+	})
+	s.timers[playlistID] = timer
+}
+
+func (s *playbackScheduler) Cancel(playlistID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.timers[playlistID]; ok {
+		t.Stop()
+		delete(s.timers, playlistID)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/logging"
+)
+
+type firing struct {
+	playlistID, trackID string
+}
+
+func newTestScheduler() (*playbackScheduler, chan firing) {
+	fired := make(chan firing, 10)
+	return newPlaybackScheduler(func(ctx context.Context, playlistID, trackID string) {
+		fired <- firing{playlistID, trackID}
+	}), fired
+}
+
+// quiet fails the test if anything fires within d.
+func quiet(t *testing.T, fired chan firing, d time.Duration) {
+	t.Helper()
+	select {
+	case f := <-fired:
+		t.Errorf("fired %+v, want nothing", f)
+	case <-time.After(d):
+	}
+}
+
+func TestSchedulerFires(t *testing.T) {
+	s, fired := newTestScheduler()
+	s.Schedule(context.Background(), "p1", "t1", time.Millisecond)
+	s.Schedule(context.Background(), "p2", "t2", time.Millisecond)
+
+	got := map[firing]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case f := <-fired:
+			got[f] = true
+		case <-time.After(time.Second):
+			t.Fatal("scheduled track never fired")
+		}
+	}
+	if !got[firing{"p1", "t1"}] || !got[firing{"p2", "t2"}] {
+		t.Errorf("fired %v, want t1 in p1 and t2 in p2", got)
+	}
+	if len(s.timers) != 0 {
+		t.Errorf("%d timers left after firing, want 0", len(s.timers))
+	}
+}
+
+func TestSchedulerReplace(t *testing.T) {
+	s, fired := newTestScheduler()
+
+	// Only the last timer of a playlist fires
+	s.Schedule(context.Background(), "p1", "t1", 20*time.Millisecond)
+	s.Schedule(context.Background(), "p1", "t2", time.Millisecond)
+
+	select {
+	case f := <-fired:
+		if f != (firing{"p1", "t2"}) {
+			t.Errorf("fired %+v, want t2 in p1", f)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("scheduled track never fired")
+	}
+	quiet(t, fired, 50*time.Millisecond)
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s, fired := newTestScheduler()
+	s.Schedule(context.Background(), "p1", "t1", 10*time.Millisecond)
+	s.Cancel("p1")
+	s.Cancel("p2") // no-op without a timer
+
+	quiet(t, fired, 50*time.Millisecond)
+	if len(s.timers) != 0 {
+		t.Errorf("%d timers left after Cancel, want 0", len(s.timers))
+	}
+}
+
+func TestSchedulerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := logging.NewContext(context.Background(), logging.New(&buf, logging.FormatJSON, slog.LevelInfo))
+	done := make(chan struct{})
+	s := newPlaybackScheduler(func(ctx context.Context, playlistID, trackID string) {
+		logging.FromContext(ctx).Info("advancing")
+		close(done)
+	})
+
+	// The scheduling context may be done long before the timer fires
+	scheduleCtx, cancel := context.WithCancel(ctx)
+	s.Schedule(scheduleCtx, "p1", "t1", time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled track never fired")
+	}
+	for _, want := range []string{`"playlist_id":"p1"`, `"track_id":"t1"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q lacks %s", buf.String(), want)
+		}
+	}
+}
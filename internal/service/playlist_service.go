@@ -4,43 +4,88 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/logging"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
 	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
 	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
 )
 
 type PlaylistService interface {
-	CreatePlaylist(ctx context.Context, playlist *model.Playlist) error
+	CreatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) error
 	GetPlaylist(ctx context.Context, id string) (*model.Playlist, error)
-	UpdatePlaylist(ctx context.Context, playlist *model.Playlist) error
-	DeletePlaylist(ctx context.Context, id string, userID string, isAdmin bool) error
-	AddTrack(ctx context.Context, track *model.Playlist_Track, userID string) error
-	RemoveTrack(ctx context.Context, playlistID, trackID string, userID string) error
-	ReorderTrack(ctx context.Context, playlistID, trackID string, newPosition int, userID string) error
+	UpdatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) error
+	DeletePlaylist(ctx context.Context, id string, actor rbac.Principal) error
+	AddTrack(ctx context.Context, track *model.Playlist_Track, actor rbac.Principal) error
+	RemoveTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) error
+	ReorderTrack(ctx context.Context, playlistID, trackID string, newPosition int, actor rbac.Principal) error
 	GetCurrentTrack(ctx context.Context, playlistID string) (*model.Playlist_Track, error)
-	GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.Playlist_Track, error)
-	ModeratePlaylist(ctx context.Context, playlistID string, isModerated bool) error
-	GetPlaylistsByHost(ctx context.Context, hostID string) ([]*model.Playlist, error)
+	GetPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error)
+	ModeratePlaylist(ctx context.Context, playlistID string, isModerated bool, actor rbac.Principal) error
+	GetPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error)
+	PlayTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) (*model.Playlist_Track, error)
+	SkipTrack(ctx context.Context, playlistID string, actor rbac.Principal) (*model.Playlist_Track, error)
+	PreviousTrack(ctx context.Context, playlistID string, actor rbac.Principal) (*model.Playlist_Track, error)
+	StopPlayback(ctx context.Context, playlistID string, actor rbac.Principal) error
+}
+
+// trackEvent is the payload of events that only reference a track.
+type trackEvent struct {
+	TrackID  string `json:"track_id"`
+	Position int    `json:"position,omitempty"`
+}
+
+// nowPlayingEvent is the payload of NowPlayingChanged; Track is null once
+// playback stops.
+type nowPlayingEvent struct {
+	Track *model.Playlist_Track `json:"track"`
 }
 
 type playlistService struct {
-	repo repository.PlaylistRepository
+	repo      repository.PlaylistRepository
+	auth      *authorizer
+	moderator *moderator
+	publisher events.Publisher
+	metrics   *metrics.Metrics
+	scheduler *playbackScheduler // nil unless auto-advance is enabled
 }
 
-func NewPlaylistService(repo repository.PlaylistRepository) PlaylistService {
-	return &playlistService{repo: repo}
+// NewPlaylistService creates the playlist service. With autoAdvance, the
+// playing track is skipped automatically once its duration has elapsed.
+func NewPlaylistService(repo repository.PlaylistRepository, members repository.MemberRepository, moderation repository.ModerationRepository, publisher events.Publisher, m *metrics.Metrics, autoAdvance bool) PlaylistService {
+	s := &playlistService{
+		repo:      repo,
+		auth:      &authorizer{playlists: repo, members: members},
+		moderator: &moderator{repo: moderation},
+		publisher: publisher,
+		metrics:   m,
+	}
+	if autoAdvance {
+		s.scheduler = newPlaybackScheduler(s.autoAdvance)
+	}
+	return s
 }
 
-func (s *playlistService) CreatePlaylist(ctx context.Context, playlist *model.Playlist) error {
+func (s *playlistService) CreatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) error {
+	if !actor.Role.Can(rbac.PermPlaylistCreate) {
+		return errorsmsg.ErrForbidden
+	}
 	if err := s.validatePlaylist(playlist); err != nil {
 		return fmt.Errorf("validating playlist: %w", err)
 	}
 
+	playlist.HostID = actor.UserID
 	playlist.CreatedAt = time.Now()
 	playlist.UpdatedAt = time.Now()
-	playlist.IsModerated = false
+	playlist.IsModerated = true // New playlists are screened until an admin says otherwise
 
 	return s.repo.CreatePlaylist(ctx, playlist)
 }
@@ -56,13 +101,10 @@ func (s *playlistService) GetPlaylist(ctx context.Context, id string) (*model.Pl
 	return playlist, nil
 }
 
-func (s *playlistService) UpdatePlaylist(ctx context.Context, playlist *model.Playlist) error {
-	existing, err := s.repo.GetPlaylist(ctx, playlist.ID)
+func (s *playlistService) UpdatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) error {
+	existing, err := s.auth.authorize(ctx, playlist.ID, actor, rbac.PermPlaylistEdit)
 	if err != nil {
-		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
-			return errorsmsg.ErrPlaylistNotFound
-		}
-		return fmt.Errorf("fetching playlist: %w", err)
+		return err
 	}
 
 	if err := s.validatePlaylist(playlist); err != nil {
@@ -78,41 +120,40 @@ func (s *playlistService) UpdatePlaylist(ctx context.Context, playlist *model.Pl
 	return s.repo.UpdatePlaylist(ctx, playlist)
 }
 
-func (s *playlistService) DeletePlaylist(ctx context.Context, id string, userID string, isAdmin bool) error {
-	existing, err := s.repo.GetPlaylist(ctx, id)
-	if err != nil {
-		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
-			return errorsmsg.ErrPlaylistNotFound
-		}
-		return fmt.Errorf("fetching playlist: %w", err)
+func (s *playlistService) DeletePlaylist(ctx context.Context, id string, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, id, actor, rbac.PermPlaylistManage); err != nil {
+		return err
 	}
 
-	if !isAdmin && existing.HostID != userID {
-		return errorsmsg.ErrUnauthorized
+	if err := s.repo.DeletePlaylist(ctx, id); err != nil {
+		return err
 	}
-
-	return s.repo.DeletePlaylist(ctx, id)
+	if s.scheduler != nil {
+		s.scheduler.Cancel(id)
+	}
+	s.publisher.Drop(id)
+	return nil
 }
 
-func (s *playlistService) GetPlaylistsByHost(ctx context.Context, hostID string) ([]*model.Playlist, error) {
-	playlists, err := s.repo.GetPlaylistsByHost(ctx, hostID)
-	if err != nil {
-		return nil, fmt.Errorf("fetching host playlists: %w", err)
+func (s *playlistService) GetPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (*model.Page[*model.Playlist], error) {
+	if err := normalizeListOptions(&opts); err != nil {
+		return nil, err
 	}
-	return playlists, nil
-}
 
-func (s *playlistService) AddTrack(ctx context.Context, track *model.Playlist_Track, userID string) error {
-	playlist, err := s.repo.GetPlaylist(ctx, track.PlaylistID)
+	page, err := s.repo.ListPlaylistsByHost(ctx, hostID, filter, opts)
 	if err != nil {
-		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
-			return errorsmsg.ErrPlaylistNotFound
+		if errors.Is(err, errorsmsg.ErrInvalidSort) || errors.Is(err, errorsmsg.ErrInvalidCursor) {
+			return nil, err
 		}
-		return fmt.Errorf("fetching playlist: %w", err)
+		return nil, fmt.Errorf("fetching host playlists: %w", err)
 	}
+	return page, nil
+}
 
-	if playlist.HostID != userID {
-		return errorsmsg.ErrUnauthorized
+func (s *playlistService) AddTrack(ctx context.Context, track *model.Playlist_Track, actor rbac.Principal) error {
+	playlist, err := s.auth.authorize(ctx, track.PlaylistID, actor, rbac.PermPlaylistEdit)
+	if err != nil {
+		return err
 	}
 
 	tracks, err := s.repo.GetPlaylistTracks(ctx, track.PlaylistID)
@@ -120,24 +161,57 @@ func (s *playlistService) AddTrack(ctx context.Context, track *model.Playlist_Tr
 		return fmt.Errorf("fetching playlist tracks: %w", err)
 	}
 
+	// An existing catalog track can be added by ID; otherwise the
+	// submitted details become a new catalog entry
+	if track.TrackID != "" {
+		catalog, err := s.repo.GetTrack(ctx, track.TrackID)
+		if err != nil {
+			if errors.Is(err, errorsmsg.ErrTrackNotFound) {
+				return errorsmsg.ErrTrackNotFound
+			}
+			return fmt.Errorf("fetching track: %w", err)
+		}
+		track.Title = catalog.Title
+		track.Artist = catalog.Artist
+		track.Duration = catalog.Duration
+	} else {
+		if strings.TrimSpace(track.Title) == "" {
+			return errorsmsg.ErrInvalidTrackTitle
+		}
+		if track.Duration < 0 {
+			return errorsmsg.ErrInvalidDuration
+		}
+		track.TrackID = uuid.New().String()
+	}
+
+	rule, err := s.moderator.screen(ctx, playlist, track.Title, track.Artist)
+	if err != nil {
+		return err
+	}
+	if rule != nil && rule.Action == model.BlockReject {
+		s.moderator.audit(ctx, actor.UserID, model.AuditTrackRejected, playlist.ID, rule.ID, describeTrack(track.Title, track.Artist))
+		return errorsmsg.ErrTrackBlocked
+	}
+
 	track.Position = len(tracks) + 1
 	track.AddedAt = time.Now()
 	track.IsPlaying = false
 
-	return s.repo.AddTrack(ctx, track)
-}
-
-func (s *playlistService) RemoveTrack(ctx context.Context, playlistID, trackID string, userID string) error {
-	playlist, err := s.repo.GetPlaylist(ctx, playlistID)
-	if err != nil {
-		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
-			return errorsmsg.ErrPlaylistNotFound
-		}
-		return fmt.Errorf("fetching playlist: %w", err)
+	if err := s.repo.AddTrack(ctx, track); err != nil {
+		return err
 	}
+	if rule != nil {
+		s.moderator.flag(ctx, track, rule, actor.UserID)
+	}
+	s.metrics.TrackAdded()
 
-	if playlist.HostID != userID {
-		return errorsmsg.ErrUnauthorized
+	s.publisher.Publish(track.PlaylistID, events.TrackAdded, track)
+	return nil
+}
+
+func (s *playlistService) RemoveTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return err
 	}
 
 	if err := s.repo.RemoveTrack(ctx, playlistID, trackID); err != nil {
@@ -147,20 +221,13 @@ func (s *playlistService) RemoveTrack(ctx context.Context, playlistID, trackID s
 		return fmt.Errorf("removing track: %w", err)
 	}
 
+	s.publisher.Publish(playlistID, events.TrackRemoved, trackEvent{TrackID: trackID})
 	return nil
 }
 
-func (s *playlistService) ReorderTrack(ctx context.Context, playlistID, trackID string, newPosition int, userID string) error {
-	playlist, err := s.repo.GetPlaylist(ctx, playlistID)
-	if err != nil {
-		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
-			return errorsmsg.ErrPlaylistNotFound
-		}
-		return fmt.Errorf("fetching playlist: %w", err)
-	}
-
-	if playlist.HostID != userID {
-		return errorsmsg.ErrUnauthorized
+func (s *playlistService) ReorderTrack(ctx context.Context, playlistID, trackID string, newPosition int, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return err
 	}
 
 	tracks, err := s.repo.GetPlaylistTracks(ctx, playlistID)
@@ -172,7 +239,12 @@ func (s *playlistService) ReorderTrack(ctx context.Context, playlistID, trackID
 		return errorsmsg.ErrInvalidPosition
 	}
 
-	return s.repo.UpdateTrackPosition(ctx, playlistID, trackID, newPosition)
+	if err := s.repo.UpdateTrackPosition(ctx, playlistID, trackID, newPosition); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(playlistID, events.TrackReordered, trackEvent{TrackID: trackID, Position: newPosition})
+	return nil
 }
 
 func (s *playlistService) GetCurrentTrack(ctx context.Context, playlistID string) (*model.Playlist_Track, error) {
@@ -183,15 +255,46 @@ func (s *playlistService) GetCurrentTrack(ctx context.Context, playlistID string
 	return track, nil
 }
 
-func (s *playlistService) GetPlaylistTracks(ctx context.Context, playlistID string) ([]*model.Playlist_Track, error) {
-	tracks, err := s.repo.GetPlaylistTracks(ctx, playlistID)
+func (s *playlistService) GetPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (*model.Page[*model.Playlist_Track], error) {
+	if err := normalizeListOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetPlaylist(ctx, playlistID); err != nil {
+		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return nil, errorsmsg.ErrPlaylistNotFound
+		}
+		return nil, fmt.Errorf("fetching playlist: %w", err)
+	}
+
+	page, err := s.repo.ListPlaylistTracks(ctx, playlistID, filter, opts)
 	if err != nil {
+		if errors.Is(err, errorsmsg.ErrInvalidSort) || errors.Is(err, errorsmsg.ErrInvalidCursor) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("fetching playlist tracks: %w", err)
 	}
-	return tracks, nil
+	return page, nil
+}
+
+// normalizeListOptions applies the default page size and caps it.
+func normalizeListOptions(opts *model.ListOptions) error {
+	switch {
+	case opts.Limit < 0:
+		return errorsmsg.ErrInvalidLimit
+	case opts.Limit == 0:
+		opts.Limit = model.DefaultPageLimit
+	case opts.Limit > model.MaxPageLimit:
+		opts.Limit = model.MaxPageLimit
+	}
+	return nil
 }
 
-func (s *playlistService) ModeratePlaylist(ctx context.Context, playlistID string, isModerated bool) error {
+func (s *playlistService) ModeratePlaylist(ctx context.Context, playlistID string, isModerated bool, actor rbac.Principal) error {
+	if !actor.Role.Can(rbac.PermModerationManage) {
+		return errorsmsg.ErrForbidden
+	}
+
 	playlist, err := s.repo.GetPlaylist(ctx, playlistID)
 	if err != nil {
 		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
@@ -203,14 +306,160 @@ func (s *playlistService) ModeratePlaylist(ctx context.Context, playlistID strin
 	playlist.IsModerated = isModerated
 	playlist.UpdatedAt = time.Now()
 
-	return s.repo.UpdatePlaylist(ctx, playlist)
+	if err := s.repo.UpdatePlaylist(ctx, playlist); err != nil {
+		return fmt.Errorf("updating playlist: %w", err)
+	}
+
+	action := model.AuditModerationDisabled
+	if isModerated {
+		action = model.AuditModerationEnabled
+	}
+	s.moderator.audit(ctx, actor.UserID, action, playlist.ID, playlist.ID, "")
+	return nil
+}
+
+func (s *playlistService) PlayTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) (*model.Playlist_Track, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return nil, err
+	}
+	return s.changeCurrentTrack(ctx, playlistID, trackID)
+}
+
+func (s *playlistService) SkipTrack(ctx context.Context, playlistID string, actor rbac.Principal) (*model.Playlist_Track, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetCurrentTrack(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching current track: %w", err)
+	}
+
+	// Skipping with nothing playing starts from the top
+	position := 0
+	if current != nil {
+		position = current.Position
+	}
+	next, err := s.advance(ctx, playlistID, position)
+	if err == nil && current != nil {
+		s.metrics.TrackSkipped()
+	}
+	return next, err
+}
+
+func (s *playlistService) PreviousTrack(ctx context.Context, playlistID string, actor rbac.Principal) (*model.Playlist_Track, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetCurrentTrack(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching current track: %w", err)
+	}
+	if current == nil {
+		return nil, errorsmsg.ErrNoTrackPlaying
+	}
+
+	previous, err := s.repo.GetAdjacentTrack(ctx, playlistID, current.Position, false)
+	if err != nil {
+		return nil, fmt.Errorf("fetching previous track: %w", err)
+	}
+	// On the first track, going back restarts it
+	if previous == nil {
+		previous = current
+	}
+	return s.changeCurrentTrack(ctx, playlistID, previous.ID)
+}
+
+func (s *playlistService) StopPlayback(ctx context.Context, playlistID string, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistEdit); err != nil {
+		return err
+	}
+	return s.stopPlayback(ctx, playlistID)
+}
+
+// advance plays the track following position, stopping playback at the end
+// of the playlist.
+func (s *playlistService) advance(ctx context.Context, playlistID string, position int) (*model.Playlist_Track, error) {
+	next, err := s.repo.GetAdjacentTrack(ctx, playlistID, position, true)
+	if err != nil {
+		return nil, fmt.Errorf("fetching next track: %w", err)
+	}
+	if next == nil {
+		return nil, s.stopPlayback(ctx, playlistID)
+	}
+	return s.changeCurrentTrack(ctx, playlistID, next.ID)
+}
+
+func (s *playlistService) changeCurrentTrack(ctx context.Context, playlistID, trackID string) (*model.Playlist_Track, error) {
+	if err := s.repo.SetCurrentTrack(ctx, playlistID, trackID); err != nil {
+		switch {
+		case errors.Is(err, errorsmsg.ErrPlaylistNotFound):
+			return nil, errorsmsg.ErrPlaylistNotFound
+		case errors.Is(err, errorsmsg.ErrTrackNotFound):
+			return nil, errorsmsg.ErrTrackNotFound
+		}
+		return nil, fmt.Errorf("setting current track: %w", err)
+	}
+
+	current, err := s.repo.GetCurrentTrack(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching current track: %w", err)
+	}
+
+	if s.scheduler != nil && current != nil && current.Duration > 0 {
+		remaining := time.Duration(current.Duration) * time.Second
+		if current.StartedAt != nil {
+			remaining -= time.Since(*current.StartedAt)
+		}
+		s.scheduler.Schedule(ctx, playlistID, current.ID, remaining)
+	}
+
+	s.publisher.Publish(playlistID, events.NowPlayingChanged, nowPlayingEvent{Track: current})
+	return current, nil
+}
+
+func (s *playlistService) stopPlayback(ctx context.Context, playlistID string) error {
+	if err := s.repo.ClearCurrentTrack(ctx, playlistID); err != nil {
+		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return errorsmsg.ErrPlaylistNotFound
+		}
+		return fmt.Errorf("clearing current track: %w", err)
+	}
+	if s.scheduler != nil {
+		s.scheduler.Cancel(playlistID)
+	}
+
+	s.publisher.Publish(playlistID, events.NowPlayingChanged, nowPlayingEvent{})
+	return nil
+}
+
+// autoAdvance is called by the scheduler once trackID has played for its
+// full duration. It does nothing if the host has changed tracks since.
+func (s *playlistService) autoAdvance(ctx context.Context, playlistID, trackID string) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	logger := logging.FromContext(ctx)
+
+	current, err := s.repo.GetCurrentTrack(ctx, playlistID)
+	if err != nil {
+		logger.Error("auto-advance: fetching current track", slog.Any("err", err))
+		return
+	}
+	if current == nil || current.ID != trackID {
+		return
+	}
+
+	if _, err := s.advance(ctx, playlistID, current.Position); err != nil {
+		logger.Error("auto-advance", slog.Any("err", err))
+	}
 }
 
 func (s *playlistService) validatePlaylist(p *model.Playlist) error {
 	if p.Name == "" {
 		return errorsmsg.ErrInvalidName
 	}
-	if len(p.Name) > 255 {
+	if utf8.RuneCountInString(p.Name) > 255 {
 		return errorsmsg.ErrNameTooLong
 	}
 	return nil
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+)
+
+var host = rbac.Principal{UserID: "host", Role: rbac.RoleHost}
+
+// newPlaybackTest returns a playlist service and a playlist "p1" with n
+// tracks titled "1".."n".
+func newPlaybackTest(t *testing.T, n int, autoAdvance bool) (*playlistService, repository.Repository, *recorder, []*model.Playlist_Track) {
+	t.Helper()
+	repo := repository.NewMemoryRepository()
+	pub := &recorder{}
+	svc := NewPlaylistService(repo.GetPlaylistRepository(), repo.GetMemberRepository(), repo.GetModerationRepository(), pub, nil, autoAdvance).(*playlistService)
+	tracks := newPlaylist(t, repo, &model.Playlist{ID: "p1", Name: "p1", HostID: host.UserID}, n)
+	return svc, repo, pub, tracks
+}
+
+// playing returns the title of the playlist's current track, or "" when
+// nothing is playing.
+func playing(t *testing.T, repo repository.Repository, playlistID string) string {
+	t.Helper()
+	current, err := repo.GetCurrentTrack(context.Background(), playlistID)
+	if err != nil {
+		t.Fatalf("GetCurrentTrack: %v", err)
+	}
+	if current == nil {
+		return ""
+	}
+	return current.Title
+}
+
+// nowPlaying returns the titles announced by NowPlayingChanged events, ""
+// for playback stopping.
+func nowPlaying(pub *recorder) []string {
+	var titles []string
+	for _, data := range pub.of(events.NowPlayingChanged) {
+		title := ""
+		if track := data.(nowPlayingEvent).Track; track != nil {
+			title = track.Title
+		}
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+// scheduled reports whether the playlist has an auto-advance timer.
+func scheduled(s *playlistService, playlistID string) bool {
+	s.scheduler.mu.Lock()
+	defer s.scheduler.mu.Unlock()
+	_, ok := s.scheduler.timers[playlistID]
+	return ok
+}
+
+func TestSkipTrack(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, pub, _ := newPlaybackTest(t, 3, false)
+
+	// Skipping with nothing playing starts from the top, and skipping the
+	// last track stops playback
+	for _, want := range []string{"1", "2", "3", ""} {
+		next, err := svc.SkipTrack(ctx, "p1", host)
+		if err != nil {
+			t.Fatalf("SkipTrack: %v", err)
+		}
+		got := ""
+		if next != nil {
+			got = next.Title
+		}
+		if got != want || playing(t, repo, "p1") != want {
+			t.Errorf("SkipTrack = %q, playing %q; want %q", got, playing(t, repo, "p1"), want)
+		}
+	}
+	if got, want := nowPlaying(pub), []string{"1", "2", "3", ""}; !equalStrings(got, want) {
+		t.Errorf("now playing events = %q, want %q", got, want)
+	}
+}
+
+func TestSkipTrackEmpty(t *testing.T) {
+	svc, repo, _, _ := newPlaybackTest(t, 0, false)
+
+	next, err := svc.SkipTrack(context.Background(), "p1", host)
+	if err != nil || next != nil {
+		t.Fatalf("SkipTrack = %+v, %v; want nil, nil", next, err)
+	}
+	if got := playing(t, repo, "p1"); got != "" {
+		t.Errorf("playing %q, want nothing", got)
+	}
+}
+
+func TestPreviousTrack(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, pub, tracks := newPlaybackTest(t, 3, false)
+
+	if _, err := svc.PreviousTrack(ctx, "p1", host); !errors.Is(err, errorsmsg.ErrNoTrackPlaying) {
+		t.Fatalf("PreviousTrack with nothing playing: err = %v, want %v", err, errorsmsg.ErrNoTrackPlaying)
+	}
+
+	if _, err := svc.PlayTrack(ctx, "p1", tracks[1].ID, host); err != nil {
+		t.Fatalf("PlayTrack: %v", err)
+	}
+	// On the first track, going back restarts it
+	for _, want := range []string{"1", "1"} {
+		previous, err := svc.PreviousTrack(ctx, "p1", host)
+		if err != nil {
+			t.Fatalf("PreviousTrack: %v", err)
+		}
+		if previous.Title != want || playing(t, repo, "p1") != want {
+			t.Errorf("PreviousTrack = %q, playing %q; want %q", previous.Title, playing(t, repo, "p1"), want)
+		}
+	}
+	if got, want := nowPlaying(pub), []string{"2", "1", "1"}; !equalStrings(got, want) {
+		t.Errorf("now playing events = %q, want %q", got, want)
+	}
+}
+
+func TestPlayTrack(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, tracks := newPlaybackTest(t, 2, false)
+
+	tests := []struct {
+		name       string
+		playlistID string
+		trackID    string
+		actor      rbac.Principal
+		want       error
+	}{
+		{"unknown playlist", "missing", tracks[0].ID, host, errorsmsg.ErrPlaylistNotFound},
+		{"unknown track", "p1", "missing", host, errorsmsg.ErrTrackNotFound},
+		{"not a member", "p1", tracks[0].ID, rbac.Principal{UserID: "other", Role: rbac.RoleHost}, errorsmsg.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.PlayTrack(ctx, tt.playlistID, tt.trackID, tt.actor); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if got := playing(t, repo, "p1"); got != "" {
+				t.Errorf("playing %q after a failed PlayTrack, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestStopPlayback(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, pub, tracks := newPlaybackTest(t, 2, false)
+
+	if _, err := svc.PlayTrack(ctx, "p1", tracks[1].ID, host); err != nil {
+		t.Fatalf("PlayTrack: %v", err)
+	}
+	// Stopping twice is fine
+	for i := 0; i < 2; i++ {
+		if err := svc.StopPlayback(ctx, "p1", host); err != nil {
+			t.Fatalf("StopPlayback: %v", err)
+		}
+	}
+	if got := playing(t, repo, "p1"); got != "" {
+		t.Errorf("playing %q after StopPlayback, want nothing", got)
+	}
+	if got, want := nowPlaying(pub), []string{"2", "", ""}; !equalStrings(got, want) {
+		t.Errorf("now playing events = %q, want %q", got, want)
+	}
+
+	if err := svc.StopPlayback(ctx, "missing", host); !errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+		t.Errorf("StopPlayback of an unknown playlist: err = %v, want %v", err, errorsmsg.ErrPlaylistNotFound)
+	}
+}
+
+func TestAutoAdvance(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, tracks := newPlaybackTest(t, 2, true)
+
+	if _, err := svc.PlayTrack(ctx, "p1", tracks[0].ID, host); err != nil {
+		t.Fatalf("PlayTrack: %v", err)
+	}
+	if !scheduled(svc, "p1") {
+		t.Fatal("no auto-advance scheduled for the playing track")
+	}
+
+	// The scheduler firing moves on to the next track, and schedules it
+	svc.autoAdvance(ctx, "p1", tracks[0].ID)
+	if got := playing(t, repo, "p1"); got != "2" {
+		t.Fatalf("playing %q after auto-advance, want %q", got, "2")
+	}
+	if !scheduled(svc, "p1") {
+		t.Error("no auto-advance scheduled for the next track")
+	}
+
+	// A timer for a track the host has moved away from does nothing
+	svc.autoAdvance(ctx, "p1", tracks[0].ID)
+	if got := playing(t, repo, "p1"); got != "2" {
+		t.Errorf("playing %q after a stale auto-advance, want %q", got, "2")
+	}
+
+	// Auto-advancing past the last track stops playback
+	svc.autoAdvance(ctx, "p1", tracks[1].ID)
+	if got := playing(t, repo, "p1"); got != "" {
+		t.Errorf("playing %q after the last track, want nothing", got)
+	}
+	if scheduled(svc, "p1") {
+		t.Error("auto-advance still scheduled after playback stopped")
+	}
+}
+
+func TestAutoAdvanceCancel(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, tracks := newPlaybackTest(t, 2, true)
+
+	if _, err := svc.PlayTrack(ctx, "p1", tracks[0].ID, host); err != nil {
+		t.Fatalf("PlayTrack: %v", err)
+	}
+	if err := svc.StopPlayback(ctx, "p1", host); err != nil {
+		t.Fatalf("StopPlayback: %v", err)
+	}
+	if scheduled(svc, "p1") {
+		t.Error("auto-advance still scheduled after StopPlayback")
+	}
+
+	if _, err := svc.PlayTrack(ctx, "p1", tracks[0].ID, host); err != nil {
+		t.Fatalf("PlayTrack: %v", err)
+	}
+	if err := svc.DeletePlaylist(ctx, "p1", host); err != nil {
+		t.Fatalf("DeletePlaylist: %v", err)
+	}
+	if scheduled(svc, "p1") {
+		t.Error("auto-advance still scheduled after DeletePlaylist")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,23 +1,57 @@
 package service
 
 import (
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/auth"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
 	"github.com/dmarquinah/publist_backend/internal/repository"
 )
 
 type Service interface {
 	// Add more service methods as needed
 	PlaylistService
+	AuthService
+	SongRequestService
+	VoteService
+	MemberService
+	ModerationService
+}
+
+// Options carries the tunable settings of the services.
+type Options struct {
+	RefreshTokenTTL time.Duration
+	// AutoAdvance skips to the next track once the playing one has run for
+	// its duration.
+	AutoAdvance bool
+	// Metrics counts what happens in playlists; nil counts nothing.
+	Metrics *metrics.Metrics
 }
 
 type service struct {
 	repo            repository.Repository
 	PlaylistService // Add PlaylistService field
+	AuthService
+	SongRequestService
+	VoteService
+	MemberService
+	ModerationService
 }
 
-func NewService(repo repository.Repository) Service {
-	playlistService := NewPlaylistService(repo.GetPlaylistRepository())
+func NewService(repo repository.Repository, jwtManager *auth.JWTManager, publisher events.Publisher, opts Options) Service {
+	playlistService := &tracedPlaylistService{
+		next: NewPlaylistService(repo.GetPlaylistRepository(), repo.GetMemberRepository(), repo.GetModerationRepository(), publisher, opts.Metrics, opts.AutoAdvance),
+	}
+	authService := NewAuthService(repo.GetHostRepository(), repo.GetRefreshTokenRepository(), jwtManager, opts.RefreshTokenTTL)
+	songRequestService := NewSongRequestService(repo.GetSongRequestRepository(), repo.GetPlaylistRepository(), repo.GetMemberRepository(), repo.GetModerationRepository(), playlistService)
 	return &service{
-		repo:            repo,
-		PlaylistService: playlistService, // Initialize PlaylistService
+		repo:               repo,
+		PlaylistService:    playlistService, // Initialize PlaylistService
+		AuthService:        authService,
+		SongRequestService: songRequestService,
+		VoteService:        NewVoteService(repo.GetVoteRepository(), repo.GetPlaylistRepository(), publisher),
+		MemberService:      NewMemberService(repo.GetMemberRepository(), repo.GetHostRepository(), repo.GetPlaylistRepository()),
+		ModerationService:  NewModerationService(repo.GetModerationRepository(), repo.GetPlaylistRepository(), repo.GetMemberRepository(), playlistService),
 	}
 }
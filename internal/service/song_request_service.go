@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// A client may submit at most songRequestLimit requests to a playlist
+	// within songRequestWindow.
+	songRequestLimit  = 3
+	songRequestWindow = 10 * time.Minute
+
+	maxNicknameLength = 50
+)
+
+type SongRequestService interface {
+	// SubmitSongRequest fails with a RetryError wrapping
+	// ErrTooManySongRequests once the client has hit the request limit.
+	SubmitSongRequest(ctx context.Context, request *model.SongRequest) error
+	GetSongRequests(ctx context.Context, playlistID string, status model.SongRequestStatus, actor rbac.Principal) ([]*model.SongRequest, error)
+	ApproveSongRequest(ctx context.Context, playlistID, requestID string, actor rbac.Principal) (*model.Playlist_Track, error)
+	RejectSongRequest(ctx context.Context, playlistID, requestID string, actor rbac.Principal) error
+}
+
+type songRequestService struct {
+	repo      repository.SongRequestRepository
+	playlists repository.PlaylistRepository
+	auth      *authorizer
+	moderator *moderator
+	tracks    PlaylistService
+}
+
+func NewSongRequestService(repo repository.SongRequestRepository, playlists repository.PlaylistRepository, members repository.MemberRepository, moderation repository.ModerationRepository, tracks PlaylistService) SongRequestService {
+	return &songRequestService{
+		repo:      repo,
+		playlists: playlists,
+		auth:      &authorizer{playlists: playlists, members: members},
+		moderator: &moderator{repo: moderation},
+		tracks:    tracks,
+	}
+}
+
+func (s *songRequestService) SubmitSongRequest(ctx context.Context, request *model.SongRequest) error {
+	request.Title = strings.TrimSpace(request.Title)
+	request.Artist = strings.TrimSpace(request.Artist)
+	request.Nickname = strings.TrimSpace(request.Nickname)
+
+	if request.Title == "" || utf8.RuneCountInString(request.Title) > 255 {
+		return errorsmsg.ErrInvalidTrackTitle
+	}
+	if utf8.RuneCountInString(request.Artist) > 255 {
+		return errorsmsg.ErrInvalidArtist
+	}
+	if request.Duration < 0 {
+		return errorsmsg.ErrInvalidDuration
+	}
+	if request.Nickname == "" || utf8.RuneCountInString(request.Nickname) > maxNicknameLength {
+		return errorsmsg.ErrInvalidNickname
+	}
+
+	playlist, err := s.playlists.GetPlaylist(ctx, request.PlaylistID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return errorsmsg.ErrPlaylistNotFound
+		}
+		return fmt.Errorf("fetching playlist: %w", err)
+	}
+
+	// Only rejecting rules apply here; flagging ones are checked again when
+	// the request is approved and the track goes in.
+	rule, err := s.moderator.screen(ctx, playlist, request.Title, request.Artist)
+	if err != nil {
+		return err
+	}
+	if rule != nil && rule.Action == model.BlockReject {
+		s.moderator.audit(ctx, "", model.AuditTrackRejected, playlist.ID, rule.ID, describeTrack(request.Title, request.Artist))
+		return errorsmsg.ErrTrackBlocked
+	}
+
+	now := time.Now()
+	request.ID = uuid.New().String()
+	request.Status = model.SongRequestPending
+	request.CreatedAt = now
+
+	err = s.repo.CreateSongRequest(ctx, request, songRequestLimit, songRequestWindow)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrTooManySongRequests) || errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return err
+		}
+		return fmt.Errorf("creating song request: %w", err)
+	}
+	return nil
+}
+
+func (s *songRequestService) GetSongRequests(ctx context.Context, playlistID string, status model.SongRequestStatus, actor rbac.Principal) ([]*model.SongRequest, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistRead); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.repo.GetSongRequests(ctx, playlistID, status)
+	if err != nil {
+		return nil, fmt.Errorf("fetching song requests: %w", err)
+	}
+	return requests, nil
+}
+
+func (s *songRequestService) ApproveSongRequest(ctx context.Context, playlistID, requestID string, actor rbac.Principal) (*model.Playlist_Track, error) {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistModerate); err != nil {
+		return nil, err
+	}
+
+	request, err := s.repo.GetSongRequest(ctx, playlistID, requestID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrSongRequestNotFound) {
+			return nil, errorsmsg.ErrSongRequestNotFound
+		}
+		return nil, fmt.Errorf("fetching song request: %w", err)
+	}
+
+	track := &model.Playlist_Track{
+		ID:         uuid.New().String(),
+		PlaylistID: playlistID,
+		Title:      request.Title,
+		Artist:     request.Artist,
+		Duration:   request.Duration,
+	}
+
+	// Claim the request before adding the track so two approvals can't
+	// both add it
+	now := time.Now()
+	request.Status = model.SongRequestApproved
+	request.TrackID = track.ID
+	request.ReviewedAt = &now
+	if err := s.repo.ReviewSongRequest(ctx, request); err != nil {
+		if errors.Is(err, errorsmsg.ErrSongRequestReviewed) {
+			return nil, errorsmsg.ErrSongRequestReviewed
+		}
+		return nil, fmt.Errorf("approving song request: %w", err)
+	}
+
+	if err := s.tracks.AddTrack(ctx, track, actor); err != nil {
+		if reopenErr := s.repo.ReopenSongRequest(ctx, request.ID); reopenErr != nil {
+			return nil, fmt.Errorf("adding track: %w (reopening request: %v)", err, reopenErr)
+		}
+		return nil, fmt.Errorf("adding track: %w", err)
+	}
+
+	return track, nil
+}
+
+func (s *songRequestService) RejectSongRequest(ctx context.Context, playlistID, requestID string, actor rbac.Principal) error {
+	if _, err := s.auth.authorize(ctx, playlistID, actor, rbac.PermPlaylistModerate); err != nil {
+		return err
+	}
+
+	request, err := s.repo.GetSongRequest(ctx, playlistID, requestID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrSongRequestNotFound) {
+			return errorsmsg.ErrSongRequestNotFound
+		}
+		return fmt.Errorf("fetching song request: %w", err)
+	}
+
+	now := time.Now()
+	request.Status = model.SongRequestRejected
+	request.ReviewedAt = &now
+	if err := s.repo.ReviewSongRequest(ctx, request); err != nil {
+		if errors.Is(err, errorsmsg.ErrSongRequestReviewed) {
+			return errorsmsg.ErrSongRequestReviewed
+		}
+		return fmt.Errorf("rejecting song request: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/rbac"
+	"github.com/dmarquinah/publist_backend/internal/tracing"
+)
+
+// tracedPlaylistService adds a span around every call to next, naming the
+// playlist and the account acting on it.
+type tracedPlaylistService struct {
+	next PlaylistService
+}
+
+func (s *tracedPlaylistService) start(ctx context.Context, method, playlistID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if playlistID != "" {
+		attrs = append(attrs, tracing.PlaylistIDKey.String(playlistID))
+	}
+	return tracing.Start(ctx, "PlaylistService."+method, attrs...)
+}
+
+func actorID(actor rbac.Principal) attribute.KeyValue {
+	return semconv.EnduserID(actor.UserID)
+}
+
+func (s *tracedPlaylistService) CreatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "CreatePlaylist", playlist.ID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.CreatePlaylist(ctx, playlist, actor)
+}
+
+func (s *tracedPlaylistService) GetPlaylist(ctx context.Context, id string) (_ *model.Playlist, err error) {
+	ctx, span := s.start(ctx, "GetPlaylist", id)
+	defer func() { tracing.End(span, err) }()
+	return s.next.GetPlaylist(ctx, id)
+}
+
+func (s *tracedPlaylistService) UpdatePlaylist(ctx context.Context, playlist *model.Playlist, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "UpdatePlaylist", playlist.ID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.UpdatePlaylist(ctx, playlist, actor)
+}
+
+func (s *tracedPlaylistService) DeletePlaylist(ctx context.Context, id string, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "DeletePlaylist", id, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.DeletePlaylist(ctx, id, actor)
+}
+
+func (s *tracedPlaylistService) AddTrack(ctx context.Context, track *model.Playlist_Track, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "AddTrack", track.PlaylistID, tracing.TrackIDKey.String(track.ID), actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.AddTrack(ctx, track, actor)
+}
+
+func (s *tracedPlaylistService) RemoveTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "RemoveTrack", playlistID, tracing.TrackIDKey.String(trackID), actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.RemoveTrack(ctx, playlistID, trackID, actor)
+}
+
+func (s *tracedPlaylistService) ReorderTrack(ctx context.Context, playlistID, trackID string, newPosition int, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "ReorderTrack", playlistID, tracing.TrackIDKey.String(trackID), actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.ReorderTrack(ctx, playlistID, trackID, newPosition, actor)
+}
+
+func (s *tracedPlaylistService) GetCurrentTrack(ctx context.Context, playlistID string) (_ *model.Playlist_Track, err error) {
+	ctx, span := s.start(ctx, "GetCurrentTrack", playlistID)
+	defer func() { tracing.End(span, err) }()
+	return s.next.GetCurrentTrack(ctx, playlistID)
+}
+
+func (s *tracedPlaylistService) GetPlaylistTracks(ctx context.Context, playlistID string, filter model.TrackFilter, opts model.ListOptions) (_ *model.Page[*model.Playlist_Track], err error) {
+	ctx, span := s.start(ctx, "GetPlaylistTracks", playlistID)
+	defer func() { tracing.End(span, err) }()
+	return s.next.GetPlaylistTracks(ctx, playlistID, filter, opts)
+}
+
+func (s *tracedPlaylistService) ModeratePlaylist(ctx context.Context, playlistID string, isModerated bool, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "ModeratePlaylist", playlistID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.ModeratePlaylist(ctx, playlistID, isModerated, actor)
+}
+
+func (s *tracedPlaylistService) GetPlaylistsByHost(ctx context.Context, hostID string, filter model.PlaylistFilter, opts model.ListOptions) (_ *model.Page[*model.Playlist], err error) {
+	ctx, span := s.start(ctx, "GetPlaylistsByHost", "", semconv.EnduserID(hostID))
+	defer func() { tracing.End(span, err) }()
+	return s.next.GetPlaylistsByHost(ctx, hostID, filter, opts)
+}
+
+func (s *tracedPlaylistService) PlayTrack(ctx context.Context, playlistID, trackID string, actor rbac.Principal) (_ *model.Playlist_Track, err error) {
+	ctx, span := s.start(ctx, "PlayTrack", playlistID, tracing.TrackIDKey.String(trackID), actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.PlayTrack(ctx, playlistID, trackID, actor)
+}
+
+func (s *tracedPlaylistService) SkipTrack(ctx context.Context, playlistID string, actor rbac.Principal) (_ *model.Playlist_Track, err error) {
+	ctx, span := s.start(ctx, "SkipTrack", playlistID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.SkipTrack(ctx, playlistID, actor)
+}
+
+func (s *tracedPlaylistService) PreviousTrack(ctx context.Context, playlistID string, actor rbac.Principal) (_ *model.Playlist_Track, err error) {
+	ctx, span := s.start(ctx, "PreviousTrack", playlistID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.PreviousTrack(ctx, playlistID, actor)
+}
+
+func (s *tracedPlaylistService) StopPlayback(ctx context.Context, playlistID string, actor rbac.Principal) (err error) {
+	ctx, span := s.start(ctx, "StopPlayback", playlistID, actorID(actor))
+	defer func() { tracing.End(span, err) }()
+	return s.next.StopPlayback(ctx, playlistID, actor)
+}
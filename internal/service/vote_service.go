@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	errorsmsg "github.com/dmarquinah/publist_backend/internal/errors"
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+)
+
+type VoteService interface {
+	// Vote records an up (+1) or down (-1) vote and returns the track's new score.
+	Vote(ctx context.Context, playlistID, trackID, voterKey string, value int) (int, error)
+	RemoveVote(ctx context.Context, playlistID, trackID, voterKey string) (int, error)
+}
+
+// voteEvent is the payload of TrackVoted.
+type voteEvent struct {
+	TrackID string `json:"track_id"`
+	Score   int    `json:"score"`
+}
+
+// reorderEvent is the payload of TracksReordered: the listed tracks now
+// fill the positions they held between them, in this order.
+type reorderEvent struct {
+	TrackIDs []string `json:"track_ids"`
+}
+
+type voteService struct {
+	repo      repository.VoteRepository
+	playlists repository.PlaylistRepository
+	publisher events.Publisher
+
+	// reorderMu serializes queue reordering so an order computed from
+	// older scores can't overwrite a newer one.
+	reorderMu sync.Mutex
+}
+
+func NewVoteService(repo repository.VoteRepository, playlists repository.PlaylistRepository, publisher events.Publisher) VoteService {
+	return &voteService{
+		repo:      repo,
+		playlists: playlists,
+		publisher: publisher,
+	}
+}
+
+func (s *voteService) Vote(ctx context.Context, playlistID, trackID, voterKey string, value int) (int, error) {
+	if value != 1 && value != -1 {
+		return 0, errorsmsg.ErrInvalidVote
+	}
+
+	playlist, err := s.findTrack(ctx, playlistID, trackID)
+	if err != nil {
+		return 0, err
+	}
+
+	vote := &model.Vote{
+		PlaylistID: playlistID,
+		TrackID:    trackID,
+		VoterKey:   voterKey,
+		Value:      value,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.CastVote(ctx, vote); err != nil {
+		return 0, fmt.Errorf("casting vote: %w", err)
+	}
+
+	return s.afterVote(ctx, playlist, trackID)
+}
+
+func (s *voteService) RemoveVote(ctx context.Context, playlistID, trackID, voterKey string) (int, error) {
+	playlist, err := s.findTrack(ctx, playlistID, trackID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.RemoveVote(ctx, playlistID, trackID, voterKey); err != nil {
+		return 0, fmt.Errorf("removing vote: %w", err)
+	}
+
+	return s.afterVote(ctx, playlist, trackID)
+}
+
+// findTrack checks that the track belongs to the playlist.
+func (s *voteService) findTrack(ctx context.Context, playlistID, trackID string) (*model.Playlist, error) {
+	playlist, err := s.playlists.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		if errors.Is(err, errorsmsg.ErrPlaylistNotFound) {
+			return nil, errorsmsg.ErrPlaylistNotFound
+		}
+		return nil, fmt.Errorf("fetching playlist: %w", err)
+	}
+
+	tracks, err := s.playlists.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching playlist tracks: %w", err)
+	}
+	for _, track := range tracks {
+		if track.ID == trackID {
+			return playlist, nil
+		}
+	}
+	return nil, errorsmsg.ErrTrackNotFound
+}
+
+func (s *voteService) afterVote(ctx context.Context, playlist *model.Playlist, trackID string) (int, error) {
+	score, err := s.repo.GetTrackScore(ctx, playlist.ID, trackID)
+	if err != nil {
+		return 0, fmt.Errorf("fetching track score: %w", err)
+	}
+	s.publisher.Publish(playlist.ID, events.TrackVoted, voteEvent{TrackID: trackID, Score: score})
+
+	if playlist.VoteOrdered {
+		if err := s.reorderByScore(ctx, playlist.ID); err != nil {
+			return 0, fmt.Errorf("reordering by score: %w", err)
+		}
+	}
+	return score, nil
+}
+
+// reorderByScore sorts the tracks after the playing one by descending score,
+// keeping the current order between equal scores, and moves them all in one
+// repository transaction.
+func (s *voteService) reorderByScore(ctx context.Context, playlistID string) error {
+	s.reorderMu.Lock()
+	defer s.reorderMu.Unlock()
+
+	tracks, err := s.playlists.GetPlaylistTracks(ctx, playlistID)
+	if err != nil {
+		return err
+	}
+
+	// Everything up to and including the playing track stays pinned
+	start := 0
+	for i, track := range tracks {
+		if track.IsPlaying {
+			start = i + 1
+			break
+		}
+	}
+
+	current := tracks[start:]
+	desired := make([]*model.Playlist_Track, len(current))
+	copy(desired, current)
+	sort.SliceStable(desired, func(i, j int) bool {
+		return desired[i].Score > desired[j].Score
+	})
+
+	changed := false
+	trackIDs := make([]string, len(desired))
+	for i, track := range desired {
+		trackIDs[i] = track.ID
+		changed = changed || current[i].ID != track.ID
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := s.playlists.ReorderTracks(ctx, playlistID, trackIDs); err != nil {
+		return err
+	}
+	s.publisher.Publish(playlistID, events.TracksReordered, reorderEvent{TrackIDs: trackIDs})
+	return nil
+}
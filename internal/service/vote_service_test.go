@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmarquinah/publist_backend/internal/events"
+	"github.com/dmarquinah/publist_backend/internal/model"
+	"github.com/dmarquinah/publist_backend/internal/repository"
+)
+
+// recorder is a Publisher that keeps what it's given.
+type recorder struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	Type events.EventType
+	Data any
+}
+
+func (r *recorder) Publish(playlistID string, eventType events.EventType, data any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, recordedEvent{eventType, data})
+}
+
+func (r *recorder) Drop(playlistID string) {}
+
+// of returns the recorded events of one type, and forgets all of them.
+func (r *recorder) of(eventType events.EventType) []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var data []any
+	for _, event := range r.events {
+		if event.Type == eventType {
+			data = append(data, event.Data)
+		}
+	}
+	r.events = nil
+	return data
+}
+
+// newPlaylist creates a playlist with n tracks titled "1".."n".
+func newPlaylist(t *testing.T, repo repository.Repository, playlist *model.Playlist, n int) []*model.Playlist_Track {
+	t.Helper()
+	ctx := context.Background()
+	if playlist.HostID == "" {
+		playlist.HostID = "host"
+	}
+	if err := repo.CreatePlaylist(ctx, playlist); err != nil {
+		t.Fatalf("CreatePlaylist: %v", err)
+	}
+	tracks := make([]*model.Playlist_Track, n)
+	for i := range tracks {
+		tracks[i] = &model.Playlist_Track{
+			ID:         fmt.Sprint("entry-", i+1),
+			PlaylistID: playlist.ID,
+			TrackID:    fmt.Sprint(playlist.ID, "-track-", i+1),
+			Title:      fmt.Sprint(i + 1),
+			Artist:     "Artist",
+			Duration:   180,
+			Position:   i + 1,
+			AddedAt:    time.Now(),
+		}
+		if err := repo.AddTrack(ctx, tracks[i]); err != nil {
+			t.Fatalf("AddTrack: %v", err)
+		}
+	}
+	return tracks
+}
+
+// titles returns a playlist's track titles in position order.
+func titles(t *testing.T, repo repository.Repository, playlistID string) string {
+	t.Helper()
+	tracks, err := repo.GetPlaylistTracks(context.Background(), playlistID)
+	if err != nil {
+		t.Fatalf("GetPlaylistTracks: %v", err)
+	}
+	got := make([]string, len(tracks))
+	for i, track := range tracks {
+		got[i] = track.Title
+	}
+	return fmt.Sprint(got)
+}
+
+func TestVoteReordersByScore(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	pub := &recorder{}
+	svc := NewVoteService(repo.GetVoteRepository(), repo.GetPlaylistRepository(), pub)
+
+	tracks := newPlaylist(t, repo, &model.Playlist{ID: "p1", Name: "p1", VoteOrdered: true}, 4)
+	if err := repo.SetCurrentTrack(ctx, "p1", tracks[0].ID); err != nil {
+		t.Fatalf("SetCurrentTrack: %v", err)
+	}
+
+	// The playing track stays first; the voted track jumps the queue in
+	// one reorder
+	if _, err := svc.Vote(ctx, "p1", tracks[3].ID, "voter", 1); err != nil {
+		t.Fatalf("Vote: %v", err)
+	}
+	if got, want := titles(t, repo, "p1"), "[1 4 2 3]"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+	reorders := pub.of(events.TracksReordered)
+	want := []any{reorderEvent{TrackIDs: []string{tracks[3].ID, tracks[1].ID, tracks[2].ID}}}
+	if fmt.Sprint(reorders) != fmt.Sprint(want) {
+		t.Errorf("reorder events = %v, want %v", reorders, want)
+	}
+
+	// A vote that doesn't change the order publishes no reorder
+	if _, err := svc.Vote(ctx, "p1", tracks[3].ID, "other", 1); err != nil {
+		t.Fatalf("Vote: %v", err)
+	}
+	if reorders := pub.of(events.TracksReordered); len(reorders) != 0 {
+		t.Errorf("reorder events = %v, want none", reorders)
+	}
+}
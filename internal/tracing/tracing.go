@@ -0,0 +1,85 @@
+// Package tracing sets up OpenTelemetry tracing and the helpers the layers
+// use to add their spans to a request's trace.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/dmarquinah/publist_backend"
+	// serviceName is reported unless OTEL_SERVICE_NAME says otherwise.
+	serviceName = "publist"
+)
+
+// Attributes shared by the layers' spans.
+const (
+	PlaylistIDKey = attribute.Key("publist.playlist.id")
+	TrackIDKey    = attribute.Key("publist.track.id")
+)
+
+// Setup installs the W3C trace context and baggage propagators, so traces
+// continue across services. With export, it also installs a tracer
+// provider sending spans over OTLP/HTTP, configured by the standard
+// OTEL_EXPORTER_OTLP_* and OTEL_TRACES_SAMPLER variables; otherwise spans
+// are dropped as they end. The returned function flushes pending spans.
+func Setup(ctx context.Context, export bool) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !export {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+	// Later options win, so OTEL_SERVICE_NAME overrides the default name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing the service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the service's spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span as a child of the one in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks span as failed if err isn't nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -4,17 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/dmarquinah/publist_backend/internal/auth"
 	"github.com/dmarquinah/publist_backend/internal/config"
+	"github.com/dmarquinah/publist_backend/internal/events"
 	"github.com/dmarquinah/publist_backend/internal/handler"
+	"github.com/dmarquinah/publist_backend/internal/health"
+	"github.com/dmarquinah/publist_backend/internal/logging"
+	"github.com/dmarquinah/publist_backend/internal/metrics"
 	"github.com/dmarquinah/publist_backend/internal/middleware"
+	"github.com/dmarquinah/publist_backend/internal/migrations"
 	"github.com/dmarquinah/publist_backend/internal/repository"
 	"github.com/dmarquinah/publist_backend/internal/service"
+	"github.com/dmarquinah/publist_backend/internal/tracing"
 )
 
 func main() {
@@ -22,18 +30,86 @@ func main() {
 	// Load configuration
 	cfg := config.New()
 
-	// Initialize database
-	dbConfig := config.NewDBConfig()
-	db, err := config.NewDB(dbConfig)
+	// Log as structured records from here on, including through package log
+	slog.SetDefault(logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel))
+
+	appMetrics := metrics.New()
+	checks := health.NewRegistry(cfg.HealthCheckTimeout)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	var repo repository.Repository
+	switch cfg.Storage {
+	case config.StorageMemory:
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatal("Migrations are not available with in-memory storage")
+		}
+		log.Println("Using in-memory storage, data will not persist")
+		repo = repository.NewMemoryRepository()
+	case config.StorageDatabase:
+		// Initialize database
+		dbConfig := config.NewDBConfig()
+		db, err := config.NewDB(dbConfig)
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		defer db.Close()
+		appMetrics.RegisterDB(db, dbConfig.DBName)
+
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(db, dbConfig.Driver, os.Args[2:]); err != nil {
+				log.Fatalf("Migration failed: %v", err)
+			}
+			return
+		}
+
+		migrator, err := migrations.New(db, dbConfig.Driver)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		if dbConfig.AutoMigrate {
+			applied, err := migrator.Up(context.Background())
+			if err != nil {
+				log.Fatalf("Failed to apply migrations: %v", err)
+			}
+			log.Printf("Applied %d migration(s)", len(applied))
+		}
+		checks.Register("database", health.DB(db))
+		checks.Register("migrations", health.Migrations(migrator))
+
+		switch dbConfig.Driver {
+		case config.DriverPostgres:
+			repo = repository.NewPostgresRepository(db)
+		case config.DriverSQLite:
+			repo = repository.NewSQLiteRepository(db)
+		default:
+			repo = repository.NewRepository(db)
+		}
+	default:
+		log.Fatalf("Unknown STORAGE %q", cfg.Storage)
+	}
+
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
 	}
-	defer db.Close()
 
 	// Initialize dependencies
-	repo := repository.NewRepository(db)
-	svc := service.NewService(repo)
-	handlers := handler.NewHandler(svc)
+	hub := events.NewHub(events.DefaultReplaySize, events.DefaultRetention)
+	appMetrics.RegisterSubscriptions(hub.Subscribers)
+	checks.Register("events", health.Hub(hub))
+	if cfg.CacheAddress != "" {
+		checks.RegisterOptional("cache", health.Cache(cfg.CacheAddress))
+	}
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret, cfg.AccessTokenTTL)
+	svc := service.NewService(repo, jwtManager, hub, service.Options{
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		AutoAdvance:     cfg.AutoAdvance,
+		Metrics:         appMetrics,
+	})
+	handlers := handler.NewHandler(svc, jwtManager, hub, appMetrics)
 
 	// Setup router
 	mux := http.NewServeMux()
@@ -44,19 +120,51 @@ func main() {
 	// Register routes
 	handlers.RegisterRoutes(apiV1)
 
-	// Mount API v1 routes under /api/v1
-	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", apiV1))
+	// Mount API v1 routes under /api/v1, rate limited and measured per route
+	var api http.Handler = apiV1
+	if cfg.RateLimit {
+		limiter := middleware.NewRateLimiter(apiV1, handler.RatePolicies(), handler.DefaultRatePolicy, jwtManager)
+		api = limiter.Limit(apiV1)
+	}
+	api = middleware.Tracing(apiV1)(middleware.Metrics(appMetrics, apiV1)(api))
+	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", api))
 
-	// Health check endpoint (outside API version)
-	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Metrics endpoint, kept off the public listener or behind a token
+	metricsHandler := appMetrics.Handler()
+	if cfg.MetricsToken != "" {
+		metricsHandler = middleware.StaticToken(cfg.MetricsToken)(metricsHandler)
+	}
+	var metricsServer *http.Server
+	switch {
+	case cfg.MetricsAddress != "":
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metricsHandler)
+		metricsServer = &http.Server{
+			Addr:         cfg.MetricsAddress,
+			Handler:      metricsMux,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+		}
+	case cfg.MetricsToken != "":
+		mux.Handle("GET /metrics", metricsHandler)
+	default:
+		log.Println("Metrics are disabled, set METRICS_TOKEN or METRICS_ADDRESS to expose them")
+	}
+
+	// Health check endpoints (outside API version). /health is kept for
+	// existing probes and answers as /health/ready does.
+	mux.Handle("GET /health/live", checks.Live())
+	mux.Handle("GET /health/ready", checks.Ready())
+	mux.Handle("GET /health", checks.Ready())
 
 	// Apply global middleware
-	handler := middleware.Logger(
-		middleware.Recoverer(
-			middleware.CORS(mux),
+	handler := middleware.RequestID(
+		middleware.RealIP(cfg.TrustedProxies)(
+			middleware.Logger(
+				middleware.Recoverer(
+					middleware.CORS(mux),
+				),
+			),
 		),
 	)
 
@@ -69,6 +177,9 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// End event streams so they don't hold up the graceful shutdown
+	server.RegisterOnShutdown(hub.Close)
+
 	// Start server
 	go func() {
 		log.Printf("Starting server on %s", cfg.ServerAddress)
@@ -77,17 +188,39 @@ func main() {
 		}
 	}()
 
+	if metricsServer != nil {
+		go func() {
+			log.Printf("Serving metrics on %s", cfg.MetricsAddress)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Metrics server failed to start: %v", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// Report not ready first, so load balancers stop sending new requests
+	checks.Shutdown()
+	if cfg.ShutdownDelay > 0 {
+		log.Printf("Draining for %s before shutting down", cfg.ShutdownDelay)
+		time.Sleep(cfg.ShutdownDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(ctx)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
 
 	log.Println("Server gracefully stopped")
 }